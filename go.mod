@@ -18,6 +18,7 @@ require (
 	kmodules.xyz/monitoring-agent-api v0.0.0-20200525002655-2aa50cb10ce9
 	kmodules.xyz/objectstore-api v0.0.0-20200521103120-92080446e04d
 	kubedb.dev/apimachinery v0.14.0-beta.1
+	sigs.k8s.io/yaml v1.2.0
 	stash.appscode.dev/apimachinery v0.10.0-beta.1
 )
 
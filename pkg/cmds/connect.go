@@ -0,0 +1,173 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/kubectl/pkg/util/term"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+)
+
+var (
+	connectLong = templates.LongDesc(`
+		Open an interactive shell of the native client of a database, i.e. psql,
+		mysql, mongo or redis-cli. The client runs inside a pod of the database:
+		the primary for Postgres and MySQL group replication, and a mongos for a
+		sharded MongoDB. It is logged in with the credentials of the database
+		secret, or with those in the environment of the database container if the
+		secret can't be read, and uses TLS if the database is configured with it.
+    `)
+
+	connectExample = templates.Examples(`
+		# Open a psql shell in the primary of a postgres
+		kubedb connect pg/postgres-demo
+
+		# Open a mongo shell in a sharded mongodb
+		kubedb connect mongodb mongodb-demo -n demo`)
+)
+
+type ConnectOptions struct {
+	CmdParent string
+	Namespace string
+	Container string
+
+	NewBuilder   func() *resource.Builder
+	Config       *rest.Config
+	KubeClient   kubernetes.Interface
+	AppCatClient appcat_cs.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdConnect(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ConnectOptions{CmdParent: parent, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "connect (TYPE NAME | TYPE/NAME)",
+		Short:   i18n.T("Open an interactive shell of a database"),
+		Long:    connectLong,
+		Example: connectExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVarP(&o.Container, "container", "c", o.Container, "Container name. If omitted, the first container of the pod is used.")
+	return cmd
+}
+
+func (o *ConnectOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to connect to. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.AppCatClient, err = appcat_cs.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *ConnectOptions) Run() error {
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+	sh, err := newDBShell(db)
+	if err != nil {
+		return err
+	}
+	pod, err := sh.runningPod(o.KubeClient, db)
+	if err != nil {
+		return err
+	}
+
+	t := term.TTY{In: o.In, Out: o.Out, Raw: true}
+	if !t.IsTerminalIn() {
+		return fmt.Errorf("connect needs a terminal, use %s exec to run commands non-interactively", o.CmdParent)
+	}
+	if err := sh.loadCredentials(o.Config, o.KubeClient, o.AppCatClient, db, pod, o.Container); err != nil {
+		return err
+	}
+	sizeQueue := t.MonitorSize(t.GetSize())
+
+	fmt.Fprintf(o.ErrOut, "Connecting to %s/%s in pod %s\n", db.Namespace, db.Name, pod.Name)
+	return t.Safe(func() error {
+		return execInPod(o.Config, o.KubeClient, pod, o.Container, sh.command(), remotecommand.StreamOptions{
+			Stdin:             t.In,
+			Stdout:            t.Out,
+			Tty:               true,
+			TerminalSizeQueue: sizeQueue,
+		})
+	})
+}
+
+// singleDatabase fetches the one database that args refer to.
+func singleDatabase(newBuilder func() *resource.Builder, namespace string, args []string) (*database, error) {
+	infos, err := newBuilder().
+		Unstructured().
+		NamespaceParam(namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(true, args...).
+		SingleResourceType().
+		Latest().
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) != 1 {
+		return nil, fmt.Errorf("expected a single database, found %d", len(infos))
+	}
+	u, ok := infos[0].Object.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", infos[0].Object)
+	}
+	return newDatabase(u)
+}
@@ -0,0 +1,292 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+
+	"github.com/appscode/go/types"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// databaseResources are the resources that "all" stands for in the resource
+// arguments of a command.
+var databaseResources = []string{
+	api.ResourcePluralElasticsearch,
+	api.ResourcePluralEtcd,
+	api.ResourcePluralMariaDB,
+	api.ResourcePluralMemcached,
+	api.ResourcePluralMongoDB,
+	api.ResourcePluralMySQL,
+	api.ResourcePluralPerconaXtraDB,
+	api.ResourcePluralPgBouncer,
+	api.ResourcePluralPostgres,
+	api.ResourcePluralProxySQL,
+	api.ResourcePluralRedis,
+}
+
+// expandAll replaces "all" in the resource types of args with the KubeDB
+// database resources that are served by the cluster.
+func expandAll(mapper meta.RESTMapper, args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	var resources []string
+	for _, r := range strings.Split(args[0], ",") {
+		if r != "all" {
+			resources = append(resources, r)
+			continue
+		}
+		for _, resource := range databaseResources {
+			gvr := api.SchemeGroupVersion.WithResource(resource)
+			if _, err := mapper.KindFor(gvr); err == nil {
+				resources = append(resources, fmt.Sprintf("%s.%s", resource, gvr.Group))
+			}
+		}
+	}
+	return append([]string{strings.Join(resources, ",")}, args[1:]...)
+}
+
+// firstKey returns the first non empty value of the keys in the data of a
+// secret.
+func firstKey(data map[string][]byte, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := data[k]; ok && len(v) > 0 {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// database is the kind independent view of a KubeDB database that the
+// commands work with.
+type database struct {
+	metav1.ObjectMeta
+
+	Kind   string
+	Object runtime.Object
+
+	Version           string
+	Mode              string
+	Replicas          int32
+	StorageType       api.StorageType
+	Storage           *core.PersistentVolumeClaimSpec
+	TerminationPolicy api.TerminationPolicy
+	Paused            bool
+	Halted            *bool
+	Phase             api.DatabasePhase
+	Selector          map[string]string
+}
+
+func newDatabase(u *unstructured.Unstructured) (*database, error) {
+	db := &database{Kind: u.GetKind()}
+	if u.GroupVersionKind().Group != api.SchemeGroupVersion.Group {
+		return nil, fmt.Errorf("%s is not a KubeDB database", u.GroupVersionKind().GroupKind())
+	}
+
+	switch db.Kind {
+	case api.ResourceKindElasticsearch:
+		var obj api.Elasticsearch
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		if t := obj.Spec.Topology; t != nil {
+			db.Mode = "Topology"
+			db.Replicas = types.Int32(t.Master.Replicas) + types.Int32(t.Data.Replicas) + types.Int32(t.Client.Replicas)
+			db.Storage = t.Data.Storage
+		} else {
+			db.Mode = "Combined"
+			db.Replicas = types.Int32(obj.Spec.Replicas)
+		}
+	case api.ResourceKindEtcd:
+		var obj api.Etcd
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+	case api.ResourceKindMariaDB:
+		var obj api.MariaDB
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+	case api.ResourceKindMemcached:
+		var obj api.Memcached
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+	case api.ResourceKindMongoDB:
+		var obj api.MongoDB
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		switch t := obj.Spec.ShardTopology; {
+		case t != nil:
+			db.Mode = "Sharded"
+			db.Replicas = t.Shard.Shards*t.Shard.Replicas + t.ConfigServer.Replicas + t.Mongos.Replicas
+			db.Storage = t.Shard.Storage
+		case obj.Spec.ReplicaSet != nil:
+			db.Mode = "ReplicaSet"
+			db.Replicas = types.Int32(obj.Spec.Replicas)
+		default:
+			db.Mode = "Standalone"
+			db.Replicas = types.Int32(obj.Spec.Replicas)
+		}
+	case api.ResourceKindMySQL:
+		var obj api.MySQL
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+		db.Mode = "Standalone"
+		if obj.Spec.Topology != nil && obj.Spec.Topology.Mode != nil {
+			db.Mode = string(*obj.Spec.Topology.Mode)
+		}
+	case api.ResourceKindPerconaXtraDB:
+		var obj api.PerconaXtraDB
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+		db.Mode = "Standalone"
+		if db.Replicas > 1 {
+			db.Mode = "Cluster"
+		}
+	case api.ResourceKindPgBouncer:
+		var obj api.PgBouncer
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = obj.Spec.Version, obj.Status.Phase
+		db.Paused = obj.Spec.Paused
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+	case api.ResourceKindPostgres:
+		var obj api.Postgres
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+		if obj.Spec.StandbyMode != nil {
+			db.Mode = string(*obj.Spec.StandbyMode)
+		}
+	case api.ResourceKindProxySQL:
+		var obj api.ProxySQL
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = obj.Spec.Version, obj.Status.Phase
+		db.Paused = obj.Spec.Paused
+		db.Replicas = types.Int32(obj.Spec.Replicas)
+		if obj.Spec.Mode != nil {
+			db.Mode = string(*obj.Spec.Mode)
+		}
+	case api.ResourceKindRedis:
+		var obj api.Redis
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		db.ObjectMeta, db.Object, db.Selector = obj.ObjectMeta, &obj, obj.OffshootSelectors()
+		db.Version, db.Phase = string(obj.Spec.Version), obj.Status.Phase
+		db.StorageType, db.Storage = obj.Spec.StorageType, obj.Spec.Storage
+		db.TerminationPolicy, db.Paused, db.Halted = obj.Spec.TerminationPolicy, obj.Spec.Paused, types.BoolP(obj.Spec.Halted)
+		db.Mode = string(obj.Spec.Mode)
+		if c := obj.Spec.Cluster; obj.Spec.Mode == api.RedisModeCluster && c != nil {
+			db.Replicas = types.Int32(c.Master) * (1 + types.Int32(c.Replicas))
+		} else {
+			db.Replicas = types.Int32(obj.Spec.Replicas)
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a KubeDB database", u.GroupVersionKind().GroupKind())
+	}
+	return db, nil
+}
+
+// storageSize returns the requested size of the database volume.
+func (db *database) storageSize() string {
+	if db.StorageType == api.StorageTypeEphemeral || db.Storage == nil {
+		return ""
+	}
+	size, ok := db.Storage.Resources.Requests[core.ResourceStorage]
+	if !ok {
+		return ""
+	}
+	return size.String()
+}
+
+// readyPods counts the ready pods of the database.
+func (db *database) readyPods(client kubernetes.Interface) (int, error) {
+	pods, err := client.CoreV1().Pods(db.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(db.Selector).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	ready := 0
+	for _, pod := range pods.Items {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == core.PodReady && c.Status == core.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready, nil
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"kubedb.dev/cli/pkg/describer"
+	"kubedb.dev/cli/pkg/printer"
 
 	"github.com/spf13/cobra"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -52,15 +53,33 @@ var (
 		# Describe all postgreses
 		kubedb describe pg
 
+		# Describe a mongodb ops request along with the timeline of its conditions
+		kubedb describe mongodbopsrequests mops-upgrade
+
+		# Describe a mongodb version from the catalog
+		kubedb describe mongodbversions 4.2.3
+
+		# Print the description of a mongodb as json
+		kubedb describe mg/mongodb-demo -o json
+
+		# Print the pods of every member of a mysql group
+		kubedb describe my/mysql-demo -o jsonpath='{.topology[*].pod}'
+
  		Valid resource types include:
     		* all
     		* etcds
     		* elasticsearches
     		* postgreses
+    		* pgbouncers
     		* mysqls
+    		* mariadbs
+    		* perconaxtradbs
     		* mongodbs
+    		* proxysqls
     		* redises
     		* memcacheds
+    		* <database>opsrequests, e.g. mongodbopsrequests
+    		* <database>versions, e.g. mongodbversions
 `)
 )
 
@@ -68,9 +87,11 @@ type DescribeOptions struct {
 	CmdParent string
 	Selector  string
 	Namespace string
+	Output    string
 
 	Describer  func(*meta.RESTMapping) (describe.ResourceDescriber, error)
 	NewBuilder func() *resource.Builder
+	Printer    printer.Printer
 
 	BuilderArgs []string
 
@@ -111,7 +132,9 @@ func NewCmdDescribe(parent string, f cmdutil.Factory, streams genericclioptions.
 	cmdutil.AddFilenameOptionFlags(cmd, o.FilenameOptions, usage)
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
 	cmd.Flags().BoolVar(&o.AllNamespaces, "all-namespaces", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml|jsonpath=<template>. Human readable text is printed if not set.")
 	cmd.Flags().BoolVar(&o.DescriberSettings.ShowEvents, "show-events", o.DescriberSettings.ShowEvents, "If true, display events related to the described object.")
+	cmd.Flags().DurationVar(&describer.CertificateExpiryWindow, "cert-expiry-window", describer.CertificateExpiryWindow, "Highlight TLS certificates that expire within this duration.")
 
 	return cmd
 }
@@ -133,6 +156,13 @@ func (o *DescribeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 
 	o.BuilderArgs = args
 
+	if o.Output != "" {
+		o.Printer, err = printer.NewPrinter(o.Output)
+		if err != nil {
+			return err
+		}
+	}
+
 	o.Describer = func(mapping *meta.RESTMapping) (describe.ResourceDescriber, error) {
 		return describer.DescriberFn(f, mapping)
 	}
@@ -172,9 +202,10 @@ func (o *DescribeOptions) Run() error {
 
 	errs := sets.NewString()
 	first := true
+	var descriptions []*describer.DatabaseDescription
 	for _, info := range infos {
 		mapping := info.ResourceMapping()
-		describer, err := o.Describer(mapping)
+		d, err := o.Describer(mapping)
 		if err != nil {
 			if errs.Has(err.Error()) {
 				continue
@@ -183,7 +214,20 @@ func (o *DescribeOptions) Run() error {
 			errs.Insert(err.Error())
 			continue
 		}
-		s, err := describer.Describe(info.Namespace, info.Name, *o.DescriberSettings)
+		if o.Printer != nil {
+			desc, err := describeDatabase(d, mapping, info.Namespace, info.Name, *o.DescriberSettings)
+			if err != nil {
+				if errs.Has(err.Error()) {
+					continue
+				}
+				allErrs = append(allErrs, err)
+				errs.Insert(err.Error())
+				continue
+			}
+			descriptions = append(descriptions, desc)
+			continue
+		}
+		s, err := d.Describe(info.Namespace, info.Name, *o.DescriberSettings)
 		if err != nil {
 			if errs.Has(err.Error()) {
 				continue
@@ -200,6 +244,12 @@ func (o *DescribeOptions) Run() error {
 		}
 	}
 
+	if len(descriptions) > 0 {
+		if err := o.printDescriptions(descriptions); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
 	return utilerrors.NewAggregate(allErrs)
 }
 
@@ -215,7 +265,7 @@ func (o *DescribeOptions) DescribeMatchingResources(originalError error, resourc
 	if err != nil {
 		return err
 	}
-	describer, err := o.Describer(mapping)
+	d, err := o.Describer(mapping)
 	if err != nil {
 		return err
 	}
@@ -224,11 +274,20 @@ func (o *DescribeOptions) DescribeMatchingResources(originalError error, resourc
 		return err
 	}
 	isFound := false
+	var descriptions []*describer.DatabaseDescription
 	for ix := range infos {
 		info := infos[ix]
 		if strings.HasPrefix(info.Name, prefix) {
 			isFound = true
-			s, err := describer.Describe(info.Namespace, info.Name, *o.DescriberSettings)
+			if o.Printer != nil {
+				desc, err := describeDatabase(d, mapping, info.Namespace, info.Name, *o.DescriberSettings)
+				if err != nil {
+					return err
+				}
+				descriptions = append(descriptions, desc)
+				continue
+			}
+			s, err := d.Describe(info.Namespace, info.Name, *o.DescriberSettings)
 			if err != nil {
 				return err
 			}
@@ -238,5 +297,27 @@ func (o *DescribeOptions) DescribeMatchingResources(originalError error, resourc
 	if !isFound {
 		return originalError
 	}
+	if len(descriptions) > 0 {
+		return o.printDescriptions(descriptions)
+	}
 	return nil
 }
+
+// describeDatabase returns the structured description of a KubeDB database.
+// Only KubeDB databases can be printed in a structured output format.
+func describeDatabase(d describe.ResourceDescriber, mapping *meta.RESTMapping, namespace, name string, settings describe.DescriberSettings) (*describer.DatabaseDescription, error) {
+	dd, ok := d.(describer.DatabaseDescriber)
+	if !ok {
+		return nil, fmt.Errorf("structured output is not supported for %s", mapping.GroupVersionKind.Kind)
+	}
+	return dd.DescribeDatabase(namespace, name, settings)
+}
+
+// printDescriptions prints a single description as is and wraps more than one
+// in a list.
+func (o *DescribeOptions) printDescriptions(descriptions []*describer.DatabaseDescription) error {
+	if len(descriptions) == 1 {
+		return o.Printer.Print(descriptions[0], o.Out)
+	}
+	return o.Printer.Print(&describer.DatabaseDescriptionList{Items: descriptions}, o.Out)
+}
@@ -0,0 +1,368 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+)
+
+var (
+	execLong = templates.LongDesc(`
+		Run a statement or a script against a database with its native client,
+		without a terminal. The script is streamed to the client over stdin in the
+		primary pod of the database, and the exit code of the client is returned.
+		For a MongoDB replica set the client connects to the current primary.
+
+		--output csv|json formats the results of the queries. In csv the results of
+		several queries are separated by an empty line. It is supported for
+		Postgres, MySQL, MariaDB and PerconaXtraDB.
+    `)
+
+	execExample = templates.Examples(`
+		# Run a query against a postgres
+		kubedb exec pg/postgres-demo -c "select 1"
+
+		# Run a migration script against a mysql
+		kubedb exec mysql mysql-demo -f migration.sql
+
+		# Print the result of a query as json
+		kubedb exec pg/postgres-demo -c "select * from pg_stat_activity" -o json
+
+		# Pipe a script into a mongodb
+		cat script.js | kubedb exec mg/mongodb-demo -f -`)
+)
+
+type ExecOptions struct {
+	CmdParent string
+	Namespace string
+	Container string
+	Command   string
+	Filename  string
+	Output    string
+
+	NewBuilder   func() *resource.Builder
+	Config       *rest.Config
+	KubeClient   kubernetes.Interface
+	AppCatClient appcat_cs.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdExec(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ExecOptions{CmdParent: parent, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "exec (TYPE NAME | TYPE/NAME) (-c COMMAND | -f FILENAME) [-o csv|json]",
+		Short:   i18n.T("Run a statement or script against a database"),
+		Long:    execLong,
+		Example: execExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVarP(&o.Command, "command", "c", o.Command, "The statement to run.")
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "The script to run, - reads it from stdin.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the query result. One of: csv|json.")
+	cmd.Flags().StringVar(&o.Container, "container", o.Container, "Container name. If omitted, the first container of the pod is used.")
+	return cmd
+}
+
+func (o *ExecOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to run against. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.AppCatClient, err = appcat_cs.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *ExecOptions) Validate() error {
+	if (o.Command == "") == (o.Filename == "") {
+		return fmt.Errorf("exactly one of --command and --filename must be specified")
+	}
+	switch o.Output {
+	case "", "csv", "json":
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q, allowed formats are: csv,json", o.Output)
+}
+
+func (o *ExecOptions) Run() error {
+	var script io.Reader
+	switch {
+	case o.Command != "":
+		script = strings.NewReader(o.Command + "\n")
+	case o.Filename == "-":
+		script = o.In
+	default:
+		f, err := os.Open(o.Filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		script = f
+	}
+
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+	sh, err := newDBShell(db)
+	if err != nil {
+		return err
+	}
+	args, format, err := sh.scriptArgs(o.Output)
+	if err != nil {
+		return err
+	}
+	pod, err := sh.runningPod(o.KubeClient, db)
+	if err != nil {
+		return err
+	}
+	if err := sh.loadCredentials(o.Config, o.KubeClient, o.AppCatClient, db, pod, o.Container); err != nil {
+		return err
+	}
+
+	var stdout io.Writer = o.Out
+	var buf bytes.Buffer
+	if o.Output != "" {
+		stdout = &buf
+	}
+	err = execInPod(o.Config, o.KubeClient, pod, o.Container, sh.command(args...), remotecommand.StreamOptions{
+		Stdin:  script,
+		Stdout: stdout,
+		Stderr: o.ErrOut,
+	})
+	if o.Output != "" {
+		// print what the client returned, even if it failed half way
+		if perr := o.printResult(&buf, format); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+// scriptArgs returns the arguments that make the client run a script from
+// stdin and the format of its tabular output.
+func (sh *dbShell) scriptArgs(output string) ([]string, string, error) {
+	switch sh.Command[0] {
+	case "psql":
+		args := []string{"--no-psqlrc", "--set=ON_ERROR_STOP=1"}
+		if output != "" {
+			// psql < 12 has no --csv, unaligned output with separators that don't
+			// show up in text works with every version. The title marks the
+			// start of the result of each statement and --quiet drops the
+			// command tags, e.g. INSERT 0 1.
+			return append(args, "--quiet", "--no-align", "--pset=footer=off", "--pset=title="+resultSeparator, "--field-separator="+unitSeparator, "--record-separator-zero"), "unaligned", nil
+		}
+		return args, "", nil
+	case "mysql":
+		if output != "" {
+			// --verbose echoes every statement between delimiter lines, which
+			// separates the results of the statements
+			return []string{"--batch", "--verbose"}, "tsv", nil
+		}
+		return nil, "", nil
+	}
+	if output != "" {
+		return nil, "", fmt.Errorf("--output is not supported for %s", sh.Command[0])
+	}
+	return nil, "", nil
+}
+
+// printResult prints the results of the statements of a script. In csv the
+// results are separated by an empty line, in json the rows of all results
+// make up one list.
+func (o *ExecOptions) printResult(r io.Reader, format string) error {
+	results, err := readRows(r, format)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "csv" {
+		w := csv.NewWriter(o.Out)
+		n := 0
+		for _, rows := range results {
+			if len(rows) == 0 {
+				continue
+			}
+			if n > 0 {
+				w.Flush()
+				fmt.Fprintln(o.Out)
+			}
+			n++
+			if err := w.WriteAll(rows); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the first row is the header, the objects keep the order of the columns
+	var out bytes.Buffer
+	out.WriteString("[")
+	n := 0
+	for _, rows := range results {
+		for i := 1; i < len(rows); i++ {
+			if n > 0 {
+				out.WriteString(",")
+			}
+			n++
+			out.WriteString("\n  {")
+			for j, v := range rows[i] {
+				if j > 0 {
+					out.WriteString(", ")
+				}
+				key, _ := json.Marshal(rows[0][j])
+				value, _ := json.Marshal(v)
+				fmt.Fprintf(&out, "%s: %s", key, value)
+			}
+			out.WriteString("}")
+		}
+	}
+	if n > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("]\n")
+	_, err = out.WriteTo(o.Out)
+	return err
+}
+
+const (
+	// unitSeparator separates the columns of the unaligned output of psql.
+	// The rows are separated by NUL, which Postgres does not allow in text.
+	unitSeparator = "\x1f"
+	// resultSeparator is the title psql prints before the result of a
+	// statement.
+	resultSeparator = "\x1e"
+	// statementDelimiter is the line mysql --verbose prints before and after
+	// it echoes a statement.
+	statementDelimiter = "--------------"
+)
+
+// readRows parses the tabular output of a client into the results of the
+// statements of a script. Each result is a header followed by the rows.
+// mysql --batch separates the columns with tabs and escapes tabs, newlines
+// and backslashes in values.
+func readRows(r io.Reader, format string) ([][][]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results [][][]string
+	if format == "unaligned" {
+		records := strings.Split(string(data), "\x00")
+		// every record ends with a NUL, what follows the last one is a command
+		// tag or a newline
+		for _, record := range records[:len(records)-1] {
+			if record == resultSeparator || strings.HasSuffix(record, "\n"+resultSeparator) {
+				results = append(results, nil)
+				continue
+			}
+			if len(results) == 0 {
+				continue
+			}
+			results[len(results)-1] = append(results[len(results)-1], strings.Split(record, unitSeparator))
+		}
+		return results, checkColumns(results)
+	}
+
+	unescape := strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\0`, "\x00", `\\`, `\`)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == statementDelimiter {
+			// skip the statement and the empty line that follows it
+			for i++; i < len(lines) && lines[i] != statementDelimiter; i++ {
+			}
+			if i+1 < len(lines) && lines[i+1] == "" {
+				i++
+			}
+			results = append(results, nil)
+			continue
+		}
+		if lines[i] == "" {
+			continue
+		}
+		if len(results) == 0 {
+			results = append(results, nil)
+		}
+		fields := strings.Split(lines[i], "\t")
+		for j := range fields {
+			fields[j] = unescape.Replace(fields[j])
+		}
+		results[len(results)-1] = append(results[len(results)-1], fields)
+	}
+	return results, checkColumns(results)
+}
+
+// checkColumns makes sure that every row has a value for each column of the
+// header, e.g. a value of psql that contains the unit separator doesn't.
+func checkColumns(results [][][]string) error {
+	for i, rows := range results {
+		for j := 1; j < len(rows); j++ {
+			if len(rows[j]) != len(rows[0]) {
+				return fmt.Errorf("row %d of result %d has %d columns, but the header has %d", j, i+1, len(rows[j]), len(rows[0]))
+			}
+		}
+	}
+	return nil
+}
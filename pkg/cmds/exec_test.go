@@ -0,0 +1,146 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestPrintResult(t *testing.T) {
+	cases := []struct {
+		name    string
+		format  string
+		output  string
+		csv     string
+		json    string
+		wantErr bool
+	}{
+		{
+			name:   "psql",
+			format: "unaligned",
+			output: "\x1e\x00a\x1fb\x001\x1fx\x00",
+			csv:    "a,b\n1,x\n",
+			json:   "[\n  {\"a\": \"1\", \"b\": \"x\"}\n]\n",
+		},
+		{
+			name:   "psql trailing newline",
+			format: "unaligned",
+			output: "\x1e\x00a\x1fb\x001\x1fx\x00\n",
+			csv:    "a,b\n1,x\n",
+			json:   "[\n  {\"a\": \"1\", \"b\": \"x\"}\n]\n",
+		},
+		{
+			name:   "psql empty result",
+			format: "unaligned",
+			output: "\x1e\x00a\x1fb\x00",
+			csv:    "a,b\n",
+			json:   "[]\n",
+		},
+		{
+			name:   "psql multiple statements",
+			format: "unaligned",
+			output: "\x1e\x00a\x001\x00\x1e\x00b\x1fc\x002\x1f3\x00",
+			csv:    "a\n1\n\nb,c\n2,3\n",
+			json:   "[\n  {\"a\": \"1\"},\n  {\"b\": \"2\", \"c\": \"3\"}\n]\n",
+		},
+		{
+			name:   "psql command tags",
+			format: "unaligned",
+			output: "INSERT 0 1\n\x1e\x00a\x001\x00UPDATE 1\n",
+			csv:    "a\n1\n",
+			json:   "[\n  {\"a\": \"1\"}\n]\n",
+		},
+		{
+			name:   "psql multiline value",
+			format: "unaligned",
+			output: "\x1e\x00a\x1fb\x00x,\ny\x1f\"z\"\x00",
+			csv:    "a,b\n\"x,\ny\",\"\"\"z\"\"\"\n",
+			json:   "[\n  {\"a\": \"x,\\ny\", \"b\": \"\\\"z\\\"\"}\n]\n",
+		},
+		{
+			name:    "psql row longer than header",
+			format:  "unaligned",
+			output:  "\x1e\x00a\x001\x1f2\x00",
+			wantErr: true,
+		},
+		{
+			name:   "mysql escapes",
+			format: "tsv",
+			output: "a\tb\nx\\ty\tline\\nbreak\\\\\n",
+			csv:    "a,b\nx\ty,\"line\nbreak\\\"\n",
+			json:   "[\n  {\"a\": \"x\\ty\", \"b\": \"line\\nbreak\\\\\"}\n]\n",
+		},
+		{
+			name:   "mysql trailing newline",
+			format: "tsv",
+			output: "a\n1\n\n",
+			csv:    "a\n1\n",
+			json:   "[\n  {\"a\": \"1\"}\n]\n",
+		},
+		{
+			name:   "mysql multiple statements",
+			format: "tsv",
+			output: "--------------\nselect 1 as a\n--------------\n\na\n1\n" +
+				"--------------\ninsert into t values (1)\n--------------\n\n" +
+				"--------------\nselect 2 as b,\n  3 as c\n--------------\n\nb\tc\n2\t3\n",
+			csv:  "a\n1\n\nb,c\n2,3\n",
+			json: "[\n  {\"a\": \"1\"},\n  {\"b\": \"2\", \"c\": \"3\"}\n]\n",
+		},
+		{
+			name:    "mysql row longer than header",
+			format:  "tsv",
+			output:  "a\n1\t2\n",
+			wantErr: true,
+		},
+		{
+			name:   "no output",
+			format: "tsv",
+			output: "",
+			csv:    "",
+			json:   "[]\n",
+		},
+	}
+
+	for _, c := range cases {
+		for _, output := range []string{"csv", "json"} {
+			var out bytes.Buffer
+			o := &ExecOptions{Output: output, IOStreams: genericclioptions.IOStreams{Out: &out}}
+			err := o.printResult(strings.NewReader(c.output), c.format)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("%s: expected an error for %s, got %q", c.name, output, out.String())
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: unexpected error for %s: %v", c.name, output, err)
+				continue
+			}
+			want := c.csv
+			if output == "json" {
+				want = c.json
+			}
+			if got := out.String(); got != want {
+				t.Errorf("%s: expected %s %q, got %q", c.name, output, want, got)
+			}
+		}
+	}
+}
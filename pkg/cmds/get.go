@@ -0,0 +1,316 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	getLong = templates.LongDesc(`
+		Display one or many KubeDB databases.
+		Unlike kubectl get, "all" lists every KubeDB database kind at once and the
+		table shows the version, phase, ready pods and storage of each database.
+    `)
+
+	getExample = templates.Examples(`
+		# List all databases in the current namespace
+		kubedb get all
+
+		# List all databases in all namespaces with more columns
+		kubedb get all --all-namespaces -o wide
+
+		# List the mongodbs with a label
+		kubedb get mg -l app=demo
+
+		# Print a postgres as yaml
+		kubedb get pg/postgres-demo -o yaml
+
+ 		Valid resource types include:
+    		* all
+    		* etcds
+    		* elasticsearches
+    		* postgreses
+    		* pgbouncers
+    		* mysqls
+    		* mariadbs
+    		* perconaxtradbs
+    		* mongodbs
+    		* proxysqls
+    		* redises
+    		* memcacheds
+`)
+)
+
+type GetOptions struct {
+	CmdParent string
+	Selector  string
+	Namespace string
+	Output    string
+
+	NewBuilder func() *resource.Builder
+	KubeClient kubernetes.Interface
+
+	BuilderArgs []string
+
+	AllNamespaces bool
+	NoHeaders     bool
+	ShowLabels    bool
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdGet(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &GetOptions{
+		CmdParent: parent,
+
+		IOStreams: streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "get [(-o|--output=)wide|json|yaml|name] (TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...)",
+		Short:   i18n.T("Display one or many KubeDB databases"),
+		Long:    getLong + "\n\n" + cmdutil.SuggestAPIResources("kubectl"),
+		Example: getExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: wide|json|yaml|name.")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default or wide output format, don't print headers.")
+	cmd.Flags().BoolVar(&o.ShowLabels, "show-labels", o.ShowLabels, "When printing, show all labels as the last column.")
+
+	return cmd
+}
+
+func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the type of resource to get. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	o.BuilderArgs = expandAll(mapper, args)
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *GetOptions) Validate() error {
+	switch o.Output {
+	case "", "wide", "json", "yaml", "name":
+		return nil
+	}
+	return fmt.Errorf("unable to match a printer suitable for the output format %q, allowed formats are: wide,json,yaml,name", o.Output)
+}
+
+func (o *GetOptions) Run() error {
+	r := o.NewBuilder().
+		Unstructured().
+		ContinueOnError().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		LabelSelectorParam(o.Selector).
+		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	allErrs := []error{}
+	infos, err := r.Infos()
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	switch o.Output {
+	case "json", "yaml":
+		if err := o.printObjects(infos); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	case "name":
+		p := &printers.NamePrinter{}
+		for _, info := range infos {
+			if err := p.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+	default:
+		table, errs := o.databaseTable(infos)
+		allErrs = append(allErrs, errs...)
+		p := printers.NewTablePrinter(printers.PrintOptions{
+			NoHeaders:     o.NoHeaders,
+			WithNamespace: o.AllNamespaces,
+			Wide:          o.Output == "wide",
+			ShowLabels:    o.ShowLabels,
+		})
+		if err := p.PrintObj(table, o.Out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if len(table.Rows) == 0 && len(allErrs) == 0 {
+			if o.AllNamespaces {
+				fmt.Fprintln(o.ErrOut, "No resources found")
+			} else {
+				fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// printObjects prints a single object as is and wraps more than one in a
+// List, like kubectl does.
+func (o *GetOptions) printObjects(infos []*resource.Info) error {
+	var p printers.ResourcePrinter = &printers.JSONPrinter{}
+	if o.Output == "yaml" {
+		p = &printers.YAMLPrinter{}
+	}
+	if len(infos) == 1 {
+		return p.PrintObj(infos[0].Object, o.Out)
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion("v1")
+	list.SetKind("List")
+	for _, info := range infos {
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected object type %T", info.Object)
+		}
+		list.Items = append(list.Items, *u)
+	}
+	return p.PrintObj(list, o.Out)
+}
+
+var databaseColumns = []metav1.TableColumnDefinition{
+	{Name: "Kind", Type: "string"},
+	{Name: "Name", Type: "string", Format: "name"},
+	{Name: "Version", Type: "string"},
+	{Name: "Status", Type: "string"},
+	{Name: "Ready", Type: "string"},
+	{Name: "Storage", Type: "string"},
+	{Name: "Termination", Type: "string"},
+	{Name: "Paused", Type: "boolean"},
+	{Name: "Halted", Type: "string"},
+	{Name: "Age", Type: "string"},
+	{Name: "Mode", Type: "string", Priority: 1},
+	{Name: "Storage-Type", Type: "string", Priority: 1},
+	{Name: "Storage-Class", Type: "string", Priority: 1},
+}
+
+// databaseTable builds one table for the databases of every kind, so that
+// `get all` prints a single list.
+func (o *GetOptions) databaseTable(infos []*resource.Info) (*metav1.Table, []error) {
+	var errs []error
+	table := &metav1.Table{ColumnDefinitions: databaseColumns}
+	for _, info := range infos {
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unexpected object type %T", info.Object))
+			continue
+		}
+		db, err := newDatabase(u)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ready, err := db.readyPods(o.KubeClient)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		halted := "-"
+		if db.Halted != nil {
+			halted = fmt.Sprint(*db.Halted)
+		}
+		storageClass := ""
+		if db.Storage != nil && db.Storage.StorageClassName != nil {
+			storageClass = *db.Storage.StorageClassName
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				db.Kind,
+				db.Name,
+				db.Version,
+				orNone(string(db.Phase)),
+				fmt.Sprintf("%d/%d", ready, db.Replicas),
+				orNone(db.storageSize()),
+				orNone(string(db.TerminationPolicy)),
+				db.Paused,
+				halted,
+				translateTimestampSince(db.CreationTimestamp),
+				orNone(db.Mode),
+				orNone(string(db.StorageType)),
+				orNone(storageClass),
+			},
+			Object: runtime.RawExtension{Object: u},
+		})
+	}
+	return table, errs
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
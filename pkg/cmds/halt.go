@@ -0,0 +1,360 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	haltLong = templates.LongDesc(`
+		Halt one or more databases. The KubeDB operator deletes the pods of a halted
+		database but keeps its PVCs and secrets, so that it can be unhalted later.
+		The command waits until the database is Halted and reports what was kept.
+
+		A halted database whose termination policy is Delete or WipeOut loses its data
+		when it is deleted, so such databases are only halted with --force.
+    `)
+
+	haltExample = templates.Examples(`
+		# Halt a mongodb
+		kubedb halt mg/mongodb-demo
+
+		# Halt a postgres with termination policy WipeOut
+		kubedb halt pg/postgres-demo --force
+
+		# Set spec.halted without waiting for the operator
+		kubedb halt mg/mongodb-demo --wait=false`)
+
+	unhaltLong = templates.LongDesc(`
+		Unhalt one or more halted databases and wait until they are Running again.
+    `)
+
+	unhaltExample = templates.Examples(`
+		# Unhalt a mongodb
+		kubedb unhalt mg/mongodb-demo
+
+		# Unhalt every halted database in the namespace
+		kubedb unhalt all --all`)
+)
+
+type HaltOptions struct {
+	CmdParent string
+	Selector  string
+	Namespace string
+
+	// Halted is the value spec.halted is set to, i.e. false for unhalt.
+	Halted  bool
+	All     bool
+	Force   bool
+	Wait    bool
+	Timeout time.Duration
+
+	NewBuilder func() *resource.Builder
+	KubeClient kubernetes.Interface
+
+	BuilderArgs []string
+
+	AllNamespaces bool
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdHalt(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &HaltOptions{CmdParent: parent, Halted: true, Wait: true, Timeout: 5 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "halt (TYPE [NAME | -l label | --all] | TYPE/NAME)",
+		Short:                 i18n.T("Halt databases while keeping their data"),
+		Long:                  haltLong,
+		Example:               haltExample,
+		Run:                   o.run(f),
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	o.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.Force, "force", o.Force, "If true, halt the database(s) even if their termination policy deletes the data once the database is deleted.")
+	return cmd
+}
+
+func NewCmdUnhalt(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &HaltOptions{CmdParent: parent, Halted: false, Wait: true, Timeout: 5 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "unhalt (TYPE [NAME | -l label | --all] | TYPE/NAME)",
+		Short:                 i18n.T("Unhalt halted databases"),
+		Long:                  unhaltLong,
+		Example:               unhaltExample,
+		Run:                   o.run(f),
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *HaltOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all the database(s) of the specified type(s) in the namespace.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, select the database(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for the database(s) to reach the "+string(o.targetPhase())+" phase.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for each database, zero means wait forever.")
+}
+
+func (o *HaltOptions) run(f cmdutil.Factory) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		cmdutil.CheckErr(o.Complete(f, cmd, args))
+		cmdutil.CheckErr(o.Run())
+	}
+}
+
+func (o *HaltOptions) verb() string {
+	if o.Halted {
+		return "halt"
+	}
+	return "unhalt"
+}
+
+func (o *HaltOptions) targetPhase() api.DatabasePhase {
+	if o.Halted {
+		return api.DatabasePhaseHalted
+	}
+	return api.DatabasePhaseRunning
+}
+
+func (o *HaltOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the type of database to %s. %s\n", o.verb(), cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	// like kubectl delete, databases are only selected by type with an
+	// explicit --all or a selector
+	if !o.All && o.Selector == "" && !hasResourceNames(args) {
+		return fmt.Errorf("You must specify the name of the database(s) to %s, --all or a selector (-l)", o.verb())
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	o.BuilderArgs = expandAll(mapper, args)
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *HaltOptions) Run() error {
+	r := o.NewBuilder().
+		Unstructured().
+		ContinueOnError().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		LabelSelectorParam(o.Selector).
+		SelectAllParam(o.All).
+		ResourceTypeOrNameArgs(false, o.BuilderArgs...).
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	allErrs := []error{}
+	infos, err := r.Infos()
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"halted":%t}}`, o.Halted))
+	for _, info := range infos {
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			allErrs = append(allErrs, fmt.Errorf("unexpected object type %T", info.Object))
+			continue
+		}
+		db, err := newDatabase(u)
+		if err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+		if err := o.validate(db); err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+
+		if *db.Halted == o.Halted {
+			o.printResult(u, "unchanged")
+		} else {
+			if _, err := resource.NewHelper(info.Client, info.Mapping).Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil); err != nil {
+				allErrs = append(allErrs, err)
+				continue
+			}
+			o.printResult(u, o.verb()+"ed")
+		}
+
+		if !o.Wait {
+			continue
+		}
+		if err := o.waitForPhase(info); err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+		if o.Halted {
+			if err := o.printKept(db); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// hasResourceNames reports whether the arguments name resources, either as
+// TYPE NAME... or as TYPE/NAME.
+func hasResourceNames(args []string) bool {
+	if len(args) > 1 && !strings.Contains(args[0], "/") {
+		return true
+	}
+	for _, arg := range args {
+		if strings.Contains(arg, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// validate refuses to halt databases that can not be halted or would lose
+// their data once deleted in halted state.
+func (o *HaltOptions) validate(db *database) error {
+	if db.Halted == nil {
+		return fmt.Errorf("%s %s/%s can not be halted", db.Kind, db.Namespace, db.Name)
+	}
+	if !o.Halted {
+		return nil
+	}
+	switch db.TerminationPolicy {
+	case api.TerminationPolicyDoNotTerminate:
+		return fmt.Errorf("can not halt %s %s/%s, since its termination policy is %s", db.Kind, db.Namespace, db.Name, db.TerminationPolicy)
+	case api.TerminationPolicyDelete, api.TerminationPolicyWipeOut:
+		if !o.Force {
+			return fmt.Errorf("%s %s/%s has termination policy %s, its PVCs will be deleted along with the halted database. Set the termination policy to %s or use --force", db.Kind, db.Namespace, db.Name, db.TerminationPolicy, api.TerminationPolicyHalt)
+		}
+	}
+	return nil
+}
+
+// waitForPhase polls the database until the operator reports the phase
+// requested by the command.
+func (o *HaltOptions) waitForPhase(info *resource.Info) error {
+	phase := o.targetPhase()
+	helper := resource.NewHelper(info.Client, info.Mapping)
+	condition := func() (bool, error) {
+		obj, err := helper.Get(info.Namespace, info.Name, false)
+		if err != nil {
+			return false, err
+		}
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return false, fmt.Errorf("unexpected object type %T", obj)
+		}
+		db, err := newDatabase(u)
+		if err != nil {
+			return false, err
+		}
+		return db.Phase == phase, nil
+	}
+
+	var err error
+	if o.Timeout == 0 {
+		err = wait.PollImmediateInfinite(2*time.Second, condition)
+	} else {
+		err = wait.PollImmediate(2*time.Second, o.Timeout, condition)
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for %s %s/%s to be %s", info.Mapping.GroupVersionKind.Kind, info.Namespace, info.Name, phase)
+	}
+	return err
+}
+
+// printKept lists the resources of the database that survived halting.
+func (o *HaltOptions) printKept(db *database) error {
+	opts := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(db.Selector).String()}
+
+	pvcs, err := o.KubeClient.CoreV1().PersistentVolumeClaims(db.Namespace).List(context.TODO(), opts)
+	if err != nil {
+		return err
+	}
+	var pvcNames []string
+	for _, pvc := range pvcs.Items {
+		pvcNames = append(pvcNames, pvc.Name)
+	}
+
+	secrets, err := o.KubeClient.CoreV1().Secrets(db.Namespace).List(context.TODO(), opts)
+	if err != nil {
+		return err
+	}
+	var secretNames []string
+	for _, secret := range secrets.Items {
+		secretNames = append(secretNames, secret.Name)
+	}
+
+	services, err := o.KubeClient.CoreV1().Services(db.Namespace).List(context.TODO(), opts)
+	if err != nil {
+		return err
+	}
+	var serviceNames []string
+	for _, svc := range services.Items {
+		serviceNames = append(serviceNames, svc.Name)
+	}
+
+	fmt.Fprintf(o.Out, "  Kept PersistentVolumeClaims: %s\n", orNone(strings.Join(pvcNames, ", ")))
+	fmt.Fprintf(o.Out, "  Kept Secrets: %s\n", orNone(strings.Join(secretNames, ", ")))
+	fmt.Fprintf(o.Out, "  Kept Services: %s\n", orNone(strings.Join(serviceNames, ", ")))
+	return nil
+}
+
+func (o *HaltOptions) printResult(u *unstructured.Unstructured, operation string) {
+	p := &printers.NamePrinter{Operation: operation}
+	_ = p.PrintObj(u, o.Out)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/cli/pkg/describer"
+
+	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	logsLong = templates.LongDesc(`
+		Print the logs of every pod of a database at once. Each line is prefixed
+		with the pod, its role in the database and the container it came from.
+
+		Roles are primary and replica for Postgres and MySQL, shard, configsvr and
+		mongos for a sharded MongoDB, and master, data and client for an
+		Elasticsearch with dedicated nodes.
+    `)
+
+	logsExample = templates.Examples(`
+		# Print the logs of all pods of a mysql group
+		kubedb logs mysql/mysql-demo
+
+		# Stream the logs of the mongos of a sharded mongodb
+		kubedb logs mg/mongodb-demo --role=mongos -f
+
+		# Print the last 20 lines of the last hour from the database containers only
+		kubedb logs pg/postgres-demo --since=1h --tail=20 -c postgres`)
+)
+
+type LogsOptions struct {
+	CmdParent string
+	Namespace string
+	Role      string
+	Container string
+
+	Follow    bool
+	Previous  bool
+	Prefix    bool
+	Since     time.Duration
+	TailLines int64
+
+	NewBuilder func() *resource.Builder
+	KubeClient kubernetes.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdLogs(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &LogsOptions{CmdParent: parent, Prefix: true, TailLines: -1, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "logs (TYPE NAME | TYPE/NAME) [--role=ROLE] [-c CONTAINER]",
+		Short:   i18n.T("Print the logs of all pods of a database"),
+		Long:    logsLong,
+		Example: logsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", o.Follow, "Specify if the logs should be streamed.")
+	cmd.Flags().BoolVarP(&o.Previous, "previous", "p", o.Previous, "If true, print the logs for the previous instance of the containers if they exist.")
+	cmd.Flags().BoolVar(&o.Prefix, "prefix", o.Prefix, "Prefix each log line with the pod, role and container.")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs.")
+	cmd.Flags().Int64Var(&o.TailLines, "tail", o.TailLines, "Lines of recent log file to display per container. Defaults to -1, showing all log lines.")
+	cmd.Flags().StringVar(&o.Role, "role", o.Role, "Only print the logs of pods with this role, e.g. primary, replica, shard, configsvr, mongos, master, data or client.")
+	cmd.Flags().StringVarP(&o.Container, "container", "c", o.Container, "Only print the logs of this container.")
+	return cmd
+}
+
+func (o *LogsOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to print the logs of. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+// logSource is a container whose logs are printed.
+type logSource struct {
+	pod       string
+	role      string
+	container string
+}
+
+func (s logSource) prefix() string {
+	if s.role == "" {
+		return fmt.Sprintf("[%s/%s] ", s.pod, s.container)
+	}
+	return fmt.Sprintf("[%s/%s/%s] ", s.pod, s.role, s.container)
+}
+
+func (o *LogsOptions) Run() error {
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+	pods, err := o.KubeClient.CoreV1().Pods(db.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(db.Selector).String(),
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	var sources []logSource
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		role := podRole(db, pod)
+		if o.Role != "" && role != o.Role {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if o.Container != "" && c.Name != o.Container {
+				continue
+			}
+			sources = append(sources, logSource{pod: pod.Name, role: role, container: c.Name})
+		}
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no container of %s %s/%s matches the given role and container", db.Kind, db.Namespace, db.Name)
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for _, src := range sources {
+		wg.Add(1)
+		go func(src logSource) {
+			defer wg.Done()
+			if err := o.printLogs(db.Namespace, src, &mu); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s/%s: %v", src.pod, src.container, err))
+				mu.Unlock()
+			}
+		}(src)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// printLogs copies the logs of a container line by line, so that lines of
+// different containers don't interleave.
+func (o *LogsOptions) printLogs(namespace string, src logSource, mu *sync.Mutex) error {
+	opts := &core.PodLogOptions{
+		Container: src.container,
+		Follow:    o.Follow,
+		Previous:  o.Previous,
+	}
+	if o.Since > 0 {
+		seconds := int64(o.Since.Round(time.Second).Seconds())
+		opts.SinceSeconds = &seconds
+	}
+	if o.TailLines >= 0 {
+		opts.TailLines = &o.TailLines
+	}
+
+	stream, err := o.KubeClient.CoreV1().Pods(namespace).GetLogs(src.pod, opts).Stream(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	prefix := ""
+	if o.Prefix {
+		prefix = src.prefix()
+	}
+	r := bufio.NewReader(stream)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				line += "\n"
+			}
+			mu.Lock()
+			_, werr := io.WriteString(o.Out, prefix+line)
+			mu.Unlock()
+			if werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// podRole returns the role of a pod in the database, or "" if the database
+// does not distinguish its pods.
+func podRole(db *database, pod *core.Pod) string {
+	switch obj := db.Object.(type) {
+	case *api.MongoDB:
+		switch {
+		case pod.Labels[api.MongoDBMongosLabelKey] != "":
+			return "mongos"
+		case pod.Labels[api.MongoDBConfigLabelKey] != "":
+			return "configsvr"
+		case pod.Labels[api.MongoDBShardLabelKey] != "":
+			return "shard"
+		}
+	case *api.Elasticsearch:
+		t := obj.Spec.Topology
+		owner := metav1.GetControllerOf(pod)
+		if t == nil || owner == nil {
+			return ""
+		}
+		for _, node := range []struct {
+			role string
+			spec *api.ElasticsearchNode
+		}{{"master", &t.Master}, {"data", &t.Data}, {"client", &t.Client}} {
+			if owner.Name == describer.ElasticsearchStatefulSetName(obj, node.spec) {
+				return node.role
+			}
+		}
+		return ""
+	}
+	return pod.Labels[api.LabelRole]
+}
@@ -0,0 +1,544 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+	"kubedb.dev/cli/pkg/describer"
+
+	"github.com/spf13/cobra"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	kmapi "kmodules.xyz/client-go/api/v1"
+)
+
+var (
+	opsLong = templates.LongDesc(`
+		List, approve, deny and watch the OpsRequests of the KubeDB enterprise
+		operator. OpsRequests of every database kind are handled at once, so an
+		OpsRequest can be referred to by its name alone unless the name is used by
+		more than one kind.
+    `)
+
+	opsExample = templates.Examples(`
+		# List the OpsRequests of all namespaces
+		kubedb ops list --all-namespaces
+
+		# Approve an OpsRequest
+		kubedb ops approve mg-demo-upgrade-x7k2p --reason="maintenance window"
+
+		# Deny a postgres OpsRequest
+		kubedb ops deny postgresopsrequest/pg-demo-upgrade-k8d9w --reason="not tested yet"
+
+		# Watch the conditions of an OpsRequest until it is done
+		kubedb ops watch mg-demo-upgrade-x7k2p`)
+)
+
+func NewCmdOps(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "ops",
+		Short:                 i18n.T("List, approve, deny and watch OpsRequests"),
+		Long:                  opsLong,
+		Example:               opsExample,
+		Run:                   cmdutil.DefaultSubCommandRun(streams.ErrOut),
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.AddCommand(NewCmdOpsList(parent, f, streams))
+	cmd.AddCommand(NewCmdOpsApprove(parent, f, streams))
+	cmd.AddCommand(NewCmdOpsDeny(parent, f, streams))
+	cmd.AddCommand(NewCmdOpsWatch(parent, f, streams))
+	return cmd
+}
+
+type OpsListOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	Selector      string
+	NoHeaders     bool
+
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdOpsList(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &OpsListOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "list [-l SELECTOR] [--all-namespaces]",
+		Aliases: []string{"ls"},
+		Short:   i18n.T("List the OpsRequests of every database kind"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the OpsRequests across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default output format, don't print headers (default print headers).")
+	return cmd
+}
+
+func (o *OpsListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+var opsRequestColumns = []metav1.TableColumnDefinition{
+	{Name: "Kind", Type: "string"},
+	{Name: "Name", Type: "string", Format: "name"},
+	{Name: "Database", Type: "string"},
+	{Name: "Type", Type: "string"},
+	{Name: "Status", Type: "string"},
+	{Name: "Age", Type: "string"},
+}
+
+func (o *OpsListOptions) Run() error {
+	kinds, err := servedOpsRequestKinds(o.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	table := &metav1.Table{ColumnDefinitions: opsRequestColumns}
+	for _, kind := range kinds {
+		list, err := o.DynamicClient.Resource(describer.OpsRequestResource(kind)).Namespace(o.Namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: o.Selector,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for i := range list.Items {
+			u := &list.Items[i]
+			ops, err := describer.NewOpsRequest(u)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			table.Rows = append(table.Rows, metav1.TableRow{
+				Cells: []interface{}{
+					kind,
+					ops.Name,
+					fmt.Sprintf("%s/%s", strings.ToLower(strings.TrimSuffix(kind, "OpsRequest")), ops.DatabaseRef.Name),
+					string(ops.Type),
+					orNone(string(ops.Phase)),
+					translateTimestampSince(ops.CreationTimestamp),
+				},
+				Object: runtime.RawExtension{Object: u},
+			})
+		}
+	}
+
+	p := printers.NewTablePrinter(printers.PrintOptions{
+		NoHeaders:     o.NoHeaders,
+		WithNamespace: o.AllNamespaces,
+	})
+	if err := p.PrintObj(table, o.Out); err != nil {
+		errs = append(errs, err)
+	}
+	if len(table.Rows) == 0 && len(errs) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+var (
+	opsApproveExample = templates.Examples(`
+		# Approve an OpsRequest
+		kubedb ops approve mg-demo-upgrade-x7k2p --reason="maintenance window"
+
+		# Approve an OpsRequest whose name is used by more than one kind
+		kubedb ops approve mongodbopsrequest/demo-upgrade`)
+
+	opsDenyExample = templates.Examples(`
+		# Deny an OpsRequest
+		kubedb ops deny pg-demo-upgrade-k8d9w --reason="not tested yet"`)
+)
+
+type OpsApprovalOptions struct {
+	CmdParent string
+	Namespace string
+	Approved  bool
+	Reason    string
+	User      string
+
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	Names []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdOpsApprove(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &OpsApprovalOptions{CmdParent: parent, Approved: true, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "approve (NAME | TYPE/NAME) [--reason=REASON]",
+		Short:   i18n.T("Approve OpsRequests that wait for approval"),
+		Example: opsApproveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "The reason for the approval, recorded in the condition.")
+	return cmd
+}
+
+func NewCmdOpsDeny(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &OpsApprovalOptions{CmdParent: parent, Approved: false, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "deny (NAME | TYPE/NAME) [--reason=REASON]",
+		Short:   i18n.T("Deny OpsRequests that wait for approval"),
+		Example: opsDenyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "The reason for the denial, recorded in the condition.")
+	return cmd
+}
+
+func (o *OpsApprovalOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the OpsRequest to %s. See '%s ops list'.\n", o.verb(), o.CmdParent)
+	}
+	o.Names = args
+
+	o.User, err = kubeconfigUser(f, cmd)
+	if err != nil {
+		return err
+	}
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *OpsApprovalOptions) verb() string {
+	if o.Approved {
+		return "approve"
+	}
+	return "deny"
+}
+
+func (o *OpsApprovalOptions) Run() error {
+	var errs []error
+	for _, name := range o.Names {
+		if err := o.setApproval(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// setApproval sets an Approved or Denied condition in the status of an
+// OpsRequest and removes a condition of the other type.
+func (o *OpsApprovalOptions) setApproval(arg string) error {
+	gvr, u, err := findOpsRequest(o.KubeClient, o.DynamicClient, o.Namespace, arg)
+	if err != nil {
+		return err
+	}
+
+	condType, reason, done := opsapi.AccessApproved, "ApprovedByUser", "approved"
+	if !o.Approved {
+		condType, reason, done = opsapi.AccessDenied, "DeniedByUser", "denied"
+	}
+	message := fmt.Sprintf("%s by %s", strings.Title(done), o.User)
+	if o.Reason != "" {
+		message += ": " + o.Reason
+	}
+
+	ri := o.DynamicClient.Resource(gvr).Namespace(u.GetNamespace())
+	// the operator updates the status as well, so retry on conflicts with the
+	// latest version of the OpsRequest
+	for attempt := 0; ; attempt++ {
+		ops, err := describer.NewOpsRequest(u)
+		if err != nil {
+			return err
+		}
+		switch ops.Phase {
+		case "", opsapi.OpsRequestPhaseWaitingForApproval:
+		default:
+			return fmt.Errorf("can not %s %s %s/%s in phase %s", o.verb(), ops.Kind, ops.Namespace, ops.Name, ops.Phase)
+		}
+
+		// a condition of the same type is replaced, like the operator does,
+		// and an earlier decision of the other type is dropped
+		opposite := opsapi.AccessDenied
+		if !o.Approved {
+			opposite = opsapi.AccessApproved
+		}
+		conditions := kmapi.RemoveCondition(ops.Conditions, opposite)
+		conditions = kmapi.SetCondition(conditions, kmapi.Condition{
+			Type:               condType,
+			Status:             kmapi.ConditionTrue,
+			ObservedGeneration: u.GetGeneration(),
+			Reason:             reason,
+			Message:            message,
+		})
+		items := make([]interface{}, 0, len(conditions))
+		for i := range conditions {
+			c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+			if err != nil {
+				return err
+			}
+			items = append(items, c)
+		}
+		if err := unstructured.SetNestedSlice(u.Object, items, "status", "conditions"); err != nil {
+			return err
+		}
+
+		_, err = ri.UpdateStatus(context.TODO(), u, metav1.UpdateOptions{})
+		if err == nil {
+			fmt.Fprintf(o.Out, "%s %s\n", opsRequestName(ops.Kind, ops.Name), done)
+			return nil
+		}
+		if !kerr.IsConflict(err) || attempt >= 4 {
+			return err
+		}
+		u, err = ri.Get(context.TODO(), u.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// kubeconfigUser returns the name of the user in the kubeconfig: the basic
+// auth username if there is one, otherwise the name of the kubeconfig user
+// selected by --user or by the context, which --context selects. It is
+// recorded as is and is not the identity the API server authenticates.
+func kubeconfigUser(f cmdutil.Factory, cmd *cobra.Command) (string, error) {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.Username != "" {
+		return config.Username, nil
+	}
+
+	// RawConfig ignores the overrides of the kubeconfig flags
+	if user, _ := cmd.Flags().GetString("user"); user != "" {
+		return user, nil
+	}
+	raw, err := f.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return "", err
+	}
+	contextName, _ := cmd.Flags().GetString("context")
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+	if ctx, ok := raw.Contexts[contextName]; ok && ctx.AuthInfo != "" {
+		return ctx.AuthInfo, nil
+	}
+	return "", fmt.Errorf("can not find the user of context %q in the kubeconfig", contextName)
+}
+
+var opsWatchExample = templates.Examples(`
+		# Watch an OpsRequest until it is Successful or Failed
+		kubedb ops watch mg-demo-upgrade-x7k2p`)
+
+type OpsWatchOptions struct {
+	CmdParent string
+	Namespace string
+	Timeout   time.Duration
+
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	Name string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdOpsWatch(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &OpsWatchOptions{CmdParent: parent, Timeout: 30 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "watch (NAME | TYPE/NAME)",
+		Short:   i18n.T("Print the conditions of an OpsRequest as they change"),
+		Example: opsWatchExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to watch the OpsRequest, zero means watch forever.")
+	return cmd
+}
+
+func (o *OpsWatchOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("You must specify exactly one OpsRequest to watch. See '%s ops list'.\n", o.CmdParent)
+	}
+	o.Name = args[0]
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *OpsWatchOptions) Run() error {
+	gvr, u, err := findOpsRequest(o.KubeClient, o.DynamicClient, o.Namespace, o.Name)
+	if err != nil {
+		return err
+	}
+	return watchOpsRequest(o.DynamicClient, gvr, u.GetNamespace(), u.GetName(), o.Timeout, o.Out)
+}
+
+// servedOpsRequestKinds returns the OpsRequest kinds the cluster serves.
+func servedOpsRequestKinds(kc kubernetes.Interface) ([]string, error) {
+	resources, err := kc.Discovery().ServerResourcesForGroupVersion(opsapi.SchemeGroupVersion.String())
+	if kerr.IsNotFound(err) {
+		return nil, fmt.Errorf("%s is not served by the cluster, is the KubeDB enterprise operator installed?", opsapi.SchemeGroupVersion)
+	} else if err != nil {
+		return nil, err
+	}
+	served := map[string]bool{}
+	for _, r := range resources.APIResources {
+		served[r.Kind] = true
+	}
+	var kinds []string
+	for _, kind := range describer.OpsRequestKinds() {
+		if served[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// findOpsRequest finds an OpsRequest given as NAME or TYPE/NAME, where TYPE
+// is the kind or resource of the OpsRequest. A bare NAME is looked up in every
+// kind and must be unique.
+func findOpsRequest(kc kubernetes.Interface, dc dynamic.Interface, namespace, arg string) (schema.GroupVersionResource, *unstructured.Unstructured, error) {
+	kinds, err := servedOpsRequestKinds(kc)
+	if err != nil {
+		return schema.GroupVersionResource{}, nil, err
+	}
+
+	name := arg
+	if i := strings.Index(arg, "/"); i >= 0 {
+		typ := strings.TrimSuffix(strings.ToLower(arg[:i]), "."+opsapi.SchemeGroupVersion.Group)
+		name = arg[i+1:]
+		var matched []string
+		for _, kind := range kinds {
+			if typ == strings.ToLower(kind) || typ == describer.OpsRequestResource(kind).Resource {
+				matched = append(matched, kind)
+			}
+		}
+		if len(matched) == 0 {
+			return schema.GroupVersionResource{}, nil, fmt.Errorf("%q is not an OpsRequest type served by the cluster", arg[:i])
+		}
+		kinds = matched
+	}
+
+	var (
+		found    []*unstructured.Unstructured
+		foundIn  []string
+		foundGVR schema.GroupVersionResource
+	)
+	for _, kind := range kinds {
+		gvr := describer.OpsRequestResource(kind)
+		u, err := dc.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if kerr.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return schema.GroupVersionResource{}, nil, err
+		}
+		found = append(found, u)
+		foundIn = append(foundIn, kind)
+		foundGVR = gvr
+	}
+	switch len(found) {
+	case 0:
+		return schema.GroupVersionResource{}, nil, fmt.Errorf("OpsRequest %s/%s not found", namespace, name)
+	case 1:
+		return foundGVR, found[0], nil
+	}
+	return schema.GroupVersionResource{}, nil, fmt.Errorf("%s/%s is ambiguous, it exists as %s, use TYPE/NAME", namespace, name, strings.Join(foundIn, " and "))
+}
@@ -0,0 +1,203 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+	"kubedb.dev/cli/pkg/describer"
+
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"kmodules.xyz/client-go/discovery"
+)
+
+// opsRequestName is the name the NamePrinter would print for the OpsRequest.
+func opsRequestName(kind, name string) string {
+	return fmt.Sprintf("%s.%s/%s", strings.ToLower(kind), opsapi.SchemeGroupVersion.Group, name)
+}
+
+// opsRequestResourceFor returns the OpsRequest kind and resource of a database
+// and checks that the cluster serves it.
+func opsRequestResourceFor(kc kubernetes.Interface, db *database) (string, schema.GroupVersionResource, error) {
+	kind, gvr, ok := describer.OpsRequestKindFor(db.Kind)
+	if !ok {
+		return "", gvr, fmt.Errorf("%s does not support OpsRequests", db.Kind)
+	}
+	if !discovery.ExistsGroupKind(kc.Discovery(), opsapi.SchemeGroupVersion.Group, kind) {
+		return "", gvr, fmt.Errorf("%s.%s is not served by the cluster, is the KubeDB enterprise operator installed?", kind, opsapi.SchemeGroupVersion.Group)
+	}
+	return kind, gvr, nil
+}
+
+// createOpsRequest creates an OpsRequest of the given type for the database.
+// spec holds the type specific fields of the spec.
+func createOpsRequest(kc kubernetes.Interface, dc dynamic.Interface, db *database, opsType opsapi.OpsRequestType, spec map[string]interface{}) (*describer.OpsRequest, error) {
+	kind, gvr, err := opsRequestResourceFor(kc, db)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetAPIVersion(opsapi.SchemeGroupVersion.String())
+	u.SetKind(kind)
+	u.SetNamespace(db.Namespace)
+	u.SetGenerateName(fmt.Sprintf("%s-%s-", db.Name, strings.ToLower(string(opsType))))
+	if err := unstructured.SetNestedField(u.Object, db.Name, "spec", "databaseRef", "name"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(u.Object, string(opsType), "spec", "type"); err != nil {
+		return nil, err
+	}
+
+	u, err = dc.Resource(gvr).Namespace(db.Namespace).Create(context.TODO(), u, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return describer.NewOpsRequest(u)
+}
+
+// watchOpsRequest prints the conditions of an OpsRequest as they are added
+// until it is finished or the timeout expires, zero means no timeout. The
+// changes are streamed by a watch, which is reconnected when it fails or is
+// closed by the API server; the OpsRequest is given up on only if it is
+// deleted. It returns an error unless the OpsRequest succeeded.
+func watchOpsRequest(dc dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string, timeout time.Duration, out io.Writer) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// conditions may be updated in place, so they are told apart by their content
+	seen := map[string]bool{}
+	var (
+		last    *describer.OpsRequest
+		lastErr error
+	)
+	handle := func(u *unstructured.Unstructured) (bool, error) {
+		o, err := describer.NewOpsRequest(u)
+		if err != nil {
+			return false, err
+		}
+		for _, c := range o.Conditions {
+			line := fmt.Sprintf("%s\t%s\t%s\t%s\n", c.LastTransitionTime.Format(time.RFC3339), c.Type, c.Status, c.Message)
+			if !seen[line] {
+				seen[line] = true
+				fmt.Fprint(out, line)
+			}
+		}
+		last = o
+		return o.IsFinished(), nil
+	}
+
+	ri := dc.Resource(gvr).Namespace(namespace)
+	for {
+		finished, err := streamOpsRequest(ctx, ri, gvr.GroupResource(), name, handle)
+		if finished {
+			break
+		}
+		if kerr.IsNotFound(err) {
+			return err
+		} else if _, ok := err.(conversionError); ok {
+			return err
+		}
+		if err != nil {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			switch {
+			case last != nil:
+				return fmt.Errorf("timed out watching %s %s/%s in phase %s", last.Kind, namespace, name, orNone(string(last.Phase)))
+			case lastErr != nil:
+				return fmt.Errorf("timed out watching %s %s/%s: %v", gvr.Resource, namespace, name, lastErr)
+			}
+			return fmt.Errorf("timed out watching %s %s/%s", gvr.Resource, namespace, name)
+		case <-time.After(2 * time.Second):
+		}
+	}
+
+	fmt.Fprintf(out, "%s %s\n", opsRequestName(last.Kind, last.Name), strings.ToLower(string(last.Phase)))
+	if last.Phase != opsapi.OpsRequestPhaseSuccessful {
+		return fmt.Errorf("%s %s/%s ended in phase %s", last.Kind, namespace, name, last.Phase)
+	}
+	return nil
+}
+
+// conversionError is returned by streamOpsRequest if an OpsRequest can not be
+// read. Unlike errors of the API server, it does not go away by retrying.
+type conversionError struct {
+	error
+}
+
+// streamOpsRequest lists the OpsRequest and then watches it from the listed
+// resource version, calling handle for every version of it until handle
+// reports that it is finished. It returns false if the watch ends before.
+func streamOpsRequest(ctx context.Context, ri dynamic.ResourceInterface, gr schema.GroupResource, name string, handle func(*unstructured.Unstructured) (bool, error)) (bool, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	list, err := ri.List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return false, err
+	}
+	if len(list.Items) == 0 {
+		return false, kerr.NewNotFound(gr, name)
+	}
+	if finished, err := handle(&list.Items[0]); err != nil {
+		return false, conversionError{err}
+	} else if finished {
+		return true, nil
+	}
+
+	w, err := ri.Watch(ctx, metav1.ListOptions{FieldSelector: selector, ResourceVersion: list.GetResourceVersion()})
+	if err != nil {
+		return false, err
+	}
+	defer w.Stop()
+	for event := range w.ResultChan() {
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			u, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				return false, conversionError{fmt.Errorf("unexpected object type %T", event.Object)}
+			}
+			if finished, err := handle(u); err != nil {
+				return false, conversionError{err}
+			} else if finished {
+				return true, nil
+			}
+		case watch.Deleted:
+			return false, kerr.NewNotFound(gr, name)
+		case watch.Error:
+			return false, kerr.FromObject(event.Object)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"kubedb.dev/cli/pkg/describer"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"kmodules.xyz/client-go/discovery"
+	stashv1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
+	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
+)
+
+var (
+	pauseLong = templates.LongDesc(`
+		Pause one or more databases. The KubeDB operator stops reconciling a
+		paused database, so changes to its spec are not applied until it is resumed.
+		With --backups, the Stash BackupConfigurations of the databases are paused too.
+    `)
+
+	pauseExample = templates.Examples(`
+		# Pause a mongodb
+		kubedb pause mg/mongodb-demo
+
+		# Pause every database with a label along with its backups
+		kubedb pause all -l app=demo --backups`)
+
+	resumeLong = templates.LongDesc(`
+		Resume one or more paused databases. With --backups, the Stash
+		BackupConfigurations of the databases are resumed too.
+    `)
+
+	resumeExample = templates.Examples(`
+		# Resume a mongodb
+		kubedb resume mg/mongodb-demo
+
+		# Resume every database in the namespace along with its backups
+		kubedb resume all --backups`)
+)
+
+type PauseOptions struct {
+	CmdParent string
+	Selector  string
+	Namespace string
+
+	// Paused is the value spec.paused is set to, i.e. false for resume.
+	Paused  bool
+	Backups bool
+
+	NewBuilder  func() *resource.Builder
+	StashClient stash.Interface
+
+	BuilderArgs []string
+
+	AllNamespaces bool
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdPause(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &PauseOptions{CmdParent: parent, Paused: true, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "pause (TYPE [NAME | -l label] | TYPE/NAME)",
+		Short:                 i18n.T("Pause the reconciliation of databases"),
+		Long:                  pauseLong,
+		Example:               pauseExample,
+		Run:                   o.run(f),
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdResume(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &PauseOptions{CmdParent: parent, Paused: false, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "resume (TYPE [NAME | -l label] | TYPE/NAME)",
+		Short:                 i18n.T("Resume the reconciliation of paused databases"),
+		Long:                  resumeLong,
+		Example:               resumeExample,
+		Run:                   o.run(f),
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *PauseOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, select the database(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.Backups, "backups", o.Backups, "If true, also "+o.verb()+" the Stash BackupConfigurations that target the database(s).")
+}
+
+func (o *PauseOptions) run(f cmdutil.Factory) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		cmdutil.CheckErr(o.Complete(f, cmd, args))
+		cmdutil.CheckErr(o.Run())
+	}
+}
+
+func (o *PauseOptions) verb() string {
+	if o.Paused {
+		return "pause"
+	}
+	return "resume"
+}
+
+func (o *PauseOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the type of database to %s. %s\n", o.verb(), cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	o.BuilderArgs = expandAll(mapper, args)
+
+	if o.Backups {
+		kc, err := f.KubernetesClientSet()
+		if err != nil {
+			return err
+		}
+		if !discovery.ExistsGroupKind(kc.Discovery(), stashv1beta1.SchemeGroupVersion.Group, stashv1beta1.ResourceKindBackupConfiguration) {
+			return fmt.Errorf("can not %s backups, Stash is not installed in the cluster", o.verb())
+		}
+		config, err := f.ToRESTConfig()
+		if err != nil {
+			return err
+		}
+		o.StashClient, err = stash.NewForConfig(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *PauseOptions) Run() error {
+	r := o.NewBuilder().
+		Unstructured().
+		ContinueOnError().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		LabelSelectorParam(o.Selector).
+		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	allErrs := []error{}
+	infos, err := r.Infos()
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, o.Paused))
+	changed, unchanged := 0, 0
+	for _, info := range infos {
+		u, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			allErrs = append(allErrs, fmt.Errorf("unexpected object type %T", info.Object))
+			continue
+		}
+		db, err := newDatabase(u)
+		if err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+
+		if db.Paused == o.Paused {
+			unchanged++
+			o.printResult(u, "unchanged")
+		} else {
+			if _, err := resource.NewHelper(info.Client, info.Mapping).Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil); err != nil {
+				allErrs = append(allErrs, err)
+				continue
+			}
+			changed++
+			o.printResult(u, o.verb()+"d")
+		}
+
+		if o.Backups {
+			if err := o.patchBackupConfigurations(db); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+	}
+
+	if changed+unchanged > 1 {
+		fmt.Fprintf(o.Out, "%d database(s) %sd, %d unchanged\n", changed, o.verb(), unchanged)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// patchBackupConfigurations pauses or resumes the BackupConfigurations that
+// describe would show for the database.
+func (o *PauseOptions) patchBackupConfigurations(db *database) error {
+	bcs, err := describer.BackupConfigurationsFor(o.StashClient, db.Namespace, db.Name)
+	if err != nil {
+		return err
+	}
+	if len(bcs) == 0 {
+		fmt.Fprintf(o.Out, "no BackupConfiguration targets %s/%s\n", strings.ToLower(db.Kind), db.Name)
+		return nil
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, o.Paused))
+	var errs []error
+	for _, bc := range bcs {
+		name := fmt.Sprintf("%s.%s/%s", strings.ToLower(stashv1beta1.ResourceKindBackupConfiguration), stashv1beta1.SchemeGroupVersion.Group, bc.Name)
+		if bc.Spec.Paused == o.Paused {
+			fmt.Fprintf(o.Out, "%s unchanged\n", name)
+			continue
+		}
+		_, err := o.StashClient.StashV1beta1().BackupConfigurations(bc.Namespace).Patch(context.TODO(), bc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(o.Out, "%s %sd\n", name, o.verb())
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (o *PauseOptions) printResult(u *unstructured.Unstructured, operation string) {
+	p := &printers.NamePrinter{Operation: operation}
+	_ = p.PrintObj(u, o.Out)
+}
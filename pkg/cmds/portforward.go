@@ -0,0 +1,317 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+
+	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+)
+
+var (
+	portForwardLong = templates.LongDesc(`
+		Forward a local port to a database. The primary service of the database is
+		used, i.e. the primary of Postgres and MySQL, the mongos of a sharded
+		MongoDB and the client nodes of Elasticsearch, on the standard port of the
+		database unless --port is given. A connection string for the forwarded port
+		is printed from the AppBinding of the database.
+    `)
+
+	portForwardExample = templates.Examples(`
+		# Forward localhost:5432 to the primary of a postgres
+		kubedb port-forward pg/postgres-demo
+
+		# Forward a random local port to a mongodb
+		kubedb port-forward mg/mongodb-demo --local-port=0`)
+)
+
+// databasePorts are the standard ports of the databases.
+var databasePorts = map[string]int32{
+	api.ResourceKindElasticsearch: api.ElasticsearchRestPort,
+	api.ResourceKindEtcd:          2379,
+	api.ResourceKindMariaDB:       api.MySQLNodePort,
+	api.ResourceKindMemcached:     11211,
+	api.ResourceKindMongoDB:       api.MongoDBMongosPort,
+	api.ResourceKindMySQL:         api.MySQLNodePort,
+	api.ResourceKindPerconaXtraDB: api.MySQLNodePort,
+	api.ResourceKindPgBouncer:     5432,
+	api.ResourceKindPostgres:      5432,
+	api.ResourceKindProxySQL:      api.ProxySQLMySQLNodePort,
+	api.ResourceKindRedis:         api.RedisNodePort,
+}
+
+type PortForwardOptions struct {
+	CmdParent string
+	Namespace string
+	Address   string
+	Port      int32
+	LocalPort int32
+
+	NewBuilder   func() *resource.Builder
+	Config       *rest.Config
+	KubeClient   kubernetes.Interface
+	AppCatClient appcat_cs.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdPortForward(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &PortForwardOptions{CmdParent: parent, Address: "localhost", LocalPort: -1, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "port-forward (TYPE NAME | TYPE/NAME)",
+		Short:   i18n.T("Forward a local port to a database"),
+		Long:    portForwardLong,
+		Example: portForwardExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVar(&o.Address, "address", o.Address, "The address to listen on.")
+	cmd.Flags().Int32Var(&o.Port, "port", o.Port, "The port of the database service. Defaults to the standard port of the database.")
+	cmd.Flags().Int32Var(&o.LocalPort, "local-port", o.LocalPort, "The local port to listen on, 0 picks a random port. Defaults to the database port.")
+	return cmd
+}
+
+func (o *PortForwardOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to forward to. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.AppCatClient, err = appcat_cs.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *PortForwardOptions) Run() error {
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+
+	// the primary service of every database is named after the database
+	svc, err := o.KubeClient.CoreV1().Services(db.Namespace).Get(context.TODO(), db.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	svcPort, err := o.servicePort(db, svc)
+	if err != nil {
+		return err
+	}
+	pod, err := o.servicePod(svc)
+	if err != nil {
+		return err
+	}
+	podPort, err := containerPort(pod, svcPort)
+	if err != nil {
+		return err
+	}
+
+	localPort := o.LocalPort
+	if localPort < 0 {
+		localPort = svcPort.Port
+	}
+	dialer, err := o.dialer(pod)
+	if err != nil {
+		return err
+	}
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		close(stopCh)
+	}()
+
+	fw, err := portforward.NewOnAddresses(dialer, []string{o.Address}, []string{fmt.Sprintf("%d:%d", localPort, podPort)}, stopCh, readyCh, o.Out, o.ErrOut)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Forwarding to %s/%s:%d through pod %s\n", svc.Namespace, svc.Name, svcPort.Port, pod.Name)
+	go func() {
+		<-readyCh
+		ports, err := fw.GetPorts()
+		if err != nil || len(ports) == 0 {
+			return
+		}
+		addr := net.JoinHostPort(o.Address, strconv.Itoa(int(ports[0].Local)))
+		if s, err := o.connectionString(db, addr); err != nil {
+			fmt.Fprintf(o.ErrOut, "can not build the connection string: %v\n", err)
+		} else if s != "" {
+			fmt.Fprintf(o.Out, "Connection string: %s\n", s)
+		}
+	}()
+	return fw.ForwardPorts()
+}
+
+// servicePort returns the port of the service given by --port, the standard
+// port of the database or the only port of the service, in that order.
+func (o *PortForwardOptions) servicePort(db *database, svc *core.Service) (*core.ServicePort, error) {
+	port := o.Port
+	if port == 0 {
+		port = databasePorts[db.Kind]
+	}
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == port {
+			return &svc.Spec.Ports[i], nil
+		}
+	}
+	if o.Port == 0 && len(svc.Spec.Ports) == 1 {
+		return &svc.Spec.Ports[0], nil
+	}
+	return nil, fmt.Errorf("service %s/%s does not expose port %d", svc.Namespace, svc.Name, port)
+}
+
+// servicePod returns a running pod behind the service.
+func (o *PortForwardOptions) servicePod(svc *core.Service) (*core.Pod, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no selector", svc.Namespace, svc.Name)
+	}
+	pods, err := o.KubeClient.CoreV1().Pods(svc.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == core.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running pod behind service %s/%s", svc.Namespace, svc.Name)
+}
+
+// containerPort resolves the target port of the service port in the pod.
+func containerPort(pod *core.Pod, svcPort *core.ServicePort) (int32, error) {
+	switch {
+	case svcPort.TargetPort.Type == intstr.Int && svcPort.TargetPort.IntVal != 0:
+		return svcPort.TargetPort.IntVal, nil
+	case svcPort.TargetPort.Type == intstr.String && svcPort.TargetPort.StrVal != "":
+		for _, c := range pod.Spec.Containers {
+			for _, p := range c.Ports {
+				if p.Name == svcPort.TargetPort.StrVal {
+					return p.ContainerPort, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("pod %s has no port named %s", pod.Name, svcPort.TargetPort.StrVal)
+	}
+	return svcPort.Port, nil
+}
+
+// dialer returns the dialer of the pods/portforward subresource of the pod.
+func (o *PortForwardOptions) dialer(pod *core.Pod) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(o.Config)
+	if err != nil {
+		return nil, err
+	}
+	req := o.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL()), nil
+}
+
+// connectionString points the client config of the AppBinding of the
+// database at the local address.
+func (o *PortForwardOptions) connectionString(db *database, addr string) (string, error) {
+	binding, err := o.AppCatClient.AppcatalogV1alpha1().AppBindings(db.Namespace).Get(context.TODO(), db.Name, metav1.GetOptions{})
+	if kerr.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	var u *url.URL
+	cc := binding.Spec.ClientConfig
+	switch {
+	case cc.URL != nil:
+		u, err = url.Parse(*cc.URL)
+		if err != nil {
+			return "", err
+		}
+	case cc.Service != nil:
+		u = &url.URL{Scheme: cc.Service.Scheme, Path: cc.Service.Path, RawQuery: cc.Service.Query}
+	default:
+		return "", nil
+	}
+	u.Host = addr
+
+	if binding.Spec.Secret != nil {
+		secret, err := o.KubeClient.CoreV1().Secrets(db.Namespace).Get(context.TODO(), binding.Spec.Secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if user := firstKey(secret.Data, core.BasicAuthUsernameKey, "POSTGRES_USER"); user != "" {
+			u.User = url.User(user)
+		}
+	}
+	return u.String(), nil
+}
@@ -66,10 +66,32 @@ func NewKubeDBCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	ioStreams := genericclioptions.IOStreams{In: in, Out: out, ErrOut: err}
 
 	groups := templates.CommandGroups{
+		{
+			Message: "Basic Commands:",
+			Commands: []*cobra.Command{
+				NewCmdGet("kubedb", f, ioStreams),
+			},
+		},
+		{
+			Message: "Database Management Commands:",
+			Commands: []*cobra.Command{
+				NewCmdPause("kubedb", f, ioStreams),
+				NewCmdResume("kubedb", f, ioStreams),
+				NewCmdHalt("kubedb", f, ioStreams),
+				NewCmdUnhalt("kubedb", f, ioStreams),
+				NewCmdUpgrade("kubedb", f, ioStreams),
+				NewCmdScale("kubedb", f, ioStreams),
+				NewCmdOps("kubedb", f, ioStreams),
+			},
+		},
 		{
 			Message: "Troubleshooting and Debugging Commands:",
 			Commands: []*cobra.Command{
+				NewCmdConnect("kubedb", f, ioStreams),
+				NewCmdExec("kubedb", f, ioStreams),
+				NewCmdPortForward("kubedb", f, ioStreams),
 				NewCmdDescribe("kubedb", f, ioStreams),
+				NewCmdLogs("kubedb", f, ioStreams),
 				v.NewCmdVersion(),
 			},
 		},
@@ -0,0 +1,461 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"fmt"
+	"time"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+	"kubedb.dev/cli/pkg/describer"
+
+	"github.com/appscode/go/types"
+	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	mona "kmodules.xyz/monitoring-agent-api/api/v1"
+)
+
+var (
+	scaleLong = templates.LongDesc(`
+		Scale a database horizontally or vertically. The scaling is done by the
+		KubeDB enterprise operator through a HorizontalScaling or VerticalScaling
+		OpsRequest, which this command creates after checking the values against the
+		topology of the database.
+
+		Horizontal scaling is supported for MongoDB replica sets and sharded
+		clusters, MySQL group replication and Elasticsearch with dedicated nodes.
+		Vertical scaling is supported for MongoDB and MySQL.
+
+		--component selects the part of the database to scale:
+		  * MongoDB: shard, configsvr, mongos, standalone or exporter
+		  * MySQL: mysql or exporter
+		  * Elasticsearch: master, data or client
+    `)
+
+	scaleExample = templates.Examples(`
+		# Scale a mongodb replica set to 5 members
+		kubedb scale mg/mongodb-demo --replicas=5
+
+		# Add a shard and a mongos to a sharded mongodb
+		kubedb scale mg/mongodb-sh --shards=3 --mongos-replicas=3
+
+		# Give the config servers of a sharded mongodb more memory
+		kubedb scale mg/mongodb-sh --component=configsvr --memory=2Gi
+
+		# Scale the data nodes of an elasticsearch
+		kubedb scale es/es-demo --component=data --replicas=4 --watch`)
+)
+
+const (
+	componentStandalone = "standalone"
+	componentShard      = "shard"
+	componentConfigSvr  = "configsvr"
+	componentMongos     = "mongos"
+	componentMySQL      = "mysql"
+	componentExporter   = "exporter"
+)
+
+type ScaleOptions struct {
+	CmdParent string
+	Namespace string
+	Component string
+
+	// Replicas, Shards and MongosReplicas are negative if not set.
+	Replicas       int32
+	Shards         int32
+	MongosReplicas int32
+	CPU            string
+	Memory         string
+	Watch          bool
+	Timeout        time.Duration
+
+	NewBuilder    func() *resource.Builder
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdScale(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ScaleOptions{CmdParent: parent, Replicas: -1, Shards: -1, MongosReplicas: -1, Timeout: 30 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "scale (TYPE NAME | TYPE/NAME) [--component=COMPONENT] (--replicas=COUNT | --shards=COUNT | --mongos-replicas=COUNT | --cpu=CPU | --memory=MEMORY)",
+		Short:   i18n.T("Scale a database horizontally or vertically"),
+		Long:    scaleLong,
+		Example: scaleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVar(&o.Component, "component", o.Component, "The component of the database to scale.")
+	cmd.Flags().Int32Var(&o.Replicas, "replicas", o.Replicas, "The new number of replicas of the component.")
+	cmd.Flags().Int32Var(&o.Shards, "shards", o.Shards, "The new number of shards of a sharded MongoDB.")
+	cmd.Flags().Int32Var(&o.MongosReplicas, "mongos-replicas", o.MongosReplicas, "The new number of mongos of a sharded MongoDB.")
+	cmd.Flags().StringVar(&o.CPU, "cpu", o.CPU, "The new cpu request and limit of the component, e.g. 500m.")
+	cmd.Flags().StringVar(&o.Memory, "memory", o.Memory, "The new memory request and limit of the component, e.g. 1Gi.")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "If true, watch the OpsRequest until it is Successful or Failed.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to watch the OpsRequest, zero means watch forever.")
+	return cmd
+}
+
+func (o *ScaleOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to scale. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *ScaleOptions) horizontal() bool {
+	return o.Replicas >= 0 || o.Shards >= 0 || o.MongosReplicas >= 0
+}
+
+func (o *ScaleOptions) vertical() bool {
+	return o.CPU != "" || o.Memory != ""
+}
+
+func (o *ScaleOptions) Validate() error {
+	switch {
+	case !o.horizontal() && !o.vertical():
+		return fmt.Errorf("one of --replicas, --shards, --mongos-replicas, --cpu and --memory must be specified")
+	case o.horizontal() && o.vertical():
+		return fmt.Errorf("horizontal and vertical scaling need separate OpsRequests, specify either replicas or resources")
+	}
+	for _, q := range []string{o.CPU, o.Memory} {
+		if q == "" {
+			continue
+		}
+		if _, err := kresource.ParseQuantity(q); err != nil {
+			return fmt.Errorf("invalid quantity %q: %v", q, err)
+		}
+	}
+	return nil
+}
+
+// scaleChange is a row of the before/after comparison.
+type scaleChange struct {
+	component string
+	field     string
+	before    string
+	after     string
+}
+
+func (o *ScaleOptions) Run() error {
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		opsType opsapi.OpsRequestType
+		field   string
+		spec    interface{}
+		changes []scaleChange
+	)
+	if o.horizontal() {
+		opsType, field = opsapi.OpsRequestTypeHorizontalScaling, "horizontalScaling"
+		spec, changes, err = o.horizontalScaling(db)
+	} else {
+		opsType, field = opsapi.OpsRequestTypeVerticalScaling, "verticalScaling"
+		spec, changes, err = o.verticalScaling(db)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("%s %s/%s already has the requested size", db.Kind, db.Namespace, db.Name)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return err
+	}
+	ops, err := createOpsRequest(o.KubeClient, o.DynamicClient, db, opsType, map[string]interface{}{field: content})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s created\n\n", opsRequestName(ops.Kind, ops.Name))
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "COMPONENT\tFIELD\tBEFORE\tAFTER")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.component, c.field, c.before, c.after)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !o.Watch {
+		return nil
+	}
+	fmt.Fprintln(o.Out)
+	_, gvr, _ := describer.OpsRequestKindFor(db.Kind)
+	return watchOpsRequest(o.DynamicClient, gvr, ops.Namespace, ops.Name, o.Timeout, o.Out)
+}
+
+// replicaChange validates a new replica count and records it if it differs
+// from the current one.
+func replicaChange(changes []scaleChange, component, field string, before, after int32) ([]scaleChange, error) {
+	if after < 1 {
+		return nil, fmt.Errorf("%s of %s must be at least 1", field, component)
+	}
+	if before != after {
+		changes = append(changes, scaleChange{component, field, fmt.Sprint(before), fmt.Sprint(after)})
+	}
+	return changes, nil
+}
+
+func (o *ScaleOptions) horizontalScaling(db *database) (interface{}, []scaleChange, error) {
+	if _, ok := db.Object.(*api.MongoDB); !ok && (o.Shards >= 0 || o.MongosReplicas >= 0) {
+		return nil, nil, fmt.Errorf("--shards and --mongos-replicas only apply to MongoDB")
+	}
+
+	var changes []scaleChange
+	var err error
+	switch obj := db.Object.(type) {
+	case *api.MongoDB:
+		t := obj.Spec.ShardTopology
+		if t == nil {
+			if obj.Spec.ReplicaSet == nil {
+				return nil, nil, fmt.Errorf("a standalone MongoDB can not be scaled horizontally")
+			}
+			if o.Shards >= 0 || o.MongosReplicas >= 0 || o.Component != "" {
+				return nil, nil, fmt.Errorf("MongoDB %s/%s is a replica set, only --replicas applies", db.Namespace, db.Name)
+			}
+			changes, err = replicaChange(changes, "replicaset", "replicas", types.Int32(obj.Spec.Replicas), o.Replicas)
+			return &opsapi.MongoDBHorizontalScalingSpec{Replicas: types.Int32P(o.Replicas)}, changes, err
+		}
+
+		shards, shardReplicas := t.Shard.Shards, t.Shard.Replicas
+		configReplicas, mongosReplicas := t.ConfigServer.Replicas, t.Mongos.Replicas
+		if o.Shards >= 0 {
+			shards = o.Shards
+		}
+		if o.MongosReplicas >= 0 {
+			mongosReplicas = o.MongosReplicas
+		}
+		if o.Replicas >= 0 {
+			switch o.Component {
+			case "", componentShard:
+				shardReplicas = o.Replicas
+			case componentConfigSvr:
+				configReplicas = o.Replicas
+			case componentMongos:
+				if o.MongosReplicas >= 0 {
+					return nil, nil, fmt.Errorf("--replicas and --mongos-replicas both set the number of mongos")
+				}
+				mongosReplicas = o.Replicas
+			default:
+				return nil, nil, fmt.Errorf("unknown component %q of a sharded MongoDB, one of: %s, %s, %s", o.Component, componentShard, componentConfigSvr, componentMongos)
+			}
+		}
+
+		spec := &opsapi.MongoDBHorizontalScalingSpec{}
+		if changes, err = replicaChange(changes, componentShard, "shards", t.Shard.Shards, shards); err != nil {
+			return nil, nil, err
+		}
+		if changes, err = replicaChange(changes, componentShard, "replicas", t.Shard.Replicas, shardReplicas); err != nil {
+			return nil, nil, err
+		}
+		if len(changes) > 0 {
+			spec.Shard = &opsapi.MongoDBShardNode{Shards: shards, Replicas: shardReplicas}
+		}
+		n := len(changes)
+		if changes, err = replicaChange(changes, componentConfigSvr, "replicas", t.ConfigServer.Replicas, configReplicas); err != nil {
+			return nil, nil, err
+		}
+		if len(changes) > n {
+			spec.ConfigServer = &opsapi.ConfigNode{Replicas: configReplicas}
+		}
+		n = len(changes)
+		if changes, err = replicaChange(changes, componentMongos, "replicas", t.Mongos.Replicas, mongosReplicas); err != nil {
+			return nil, nil, err
+		}
+		if len(changes) > n {
+			spec.Mongos = &opsapi.MongosNode{Replicas: mongosReplicas}
+		}
+		return spec, changes, nil
+	case *api.MySQL:
+		if obj.Spec.Topology == nil {
+			return nil, nil, fmt.Errorf("only MySQL group replication can be scaled horizontally")
+		}
+		if o.Component != "" && o.Component != componentMySQL {
+			return nil, nil, fmt.Errorf("unknown component %q of a MySQL group, only %s can be scaled horizontally", o.Component, componentMySQL)
+		}
+		if o.Replicas > api.MySQLMaxGroupMembers {
+			return nil, nil, fmt.Errorf("a MySQL group can have at most %d members", api.MySQLMaxGroupMembers)
+		}
+		changes, err = replicaChange(changes, componentMySQL, "members", types.Int32(obj.Spec.Replicas), o.Replicas)
+		return &opsapi.MySQLHorizontalScalingSpec{Member: types.Int32P(o.Replicas)}, changes, err
+	case *api.Elasticsearch:
+		t := obj.Spec.Topology
+		if t == nil {
+			return nil, nil, fmt.Errorf("only Elasticsearch with dedicated master, data and client nodes can be scaled horizontally")
+		}
+		spec := &opsapi.ElasticsearchHorizontalScalingSpec{}
+		var node *api.ElasticsearchNode
+		switch o.Component {
+		case "master":
+			node, spec.Master = &t.Master, types.Int32P(o.Replicas)
+		case "data":
+			node, spec.Data = &t.Data, types.Int32P(o.Replicas)
+		case "client":
+			node, spec.Client = &t.Client, types.Int32P(o.Replicas)
+		default:
+			return nil, nil, fmt.Errorf("--component must be one of master, data and client for Elasticsearch")
+		}
+		changes, err = replicaChange(changes, o.Component, "replicas", types.Int32(node.Replicas), o.Replicas)
+		return spec, changes, err
+	}
+	return nil, nil, fmt.Errorf("%s can not be scaled horizontally", db.Kind)
+}
+
+func (o *ScaleOptions) verticalScaling(db *database) (interface{}, []scaleChange, error) {
+	var before core.ResourceRequirements
+	switch obj := db.Object.(type) {
+	case *api.MongoDB:
+		spec := &opsapi.MongoDBVerticalScalingSpec{}
+		var target **core.ResourceRequirements
+		t := obj.Spec.ShardTopology
+		switch {
+		case o.Component == componentExporter:
+			before, target = exporterResources(obj.Spec.Monitor), &spec.Exporter
+		case t == nil && (o.Component == "" || o.Component == componentStandalone):
+			if obj.Spec.PodTemplate != nil {
+				before = obj.Spec.PodTemplate.Spec.Resources
+			}
+			o.Component, target = componentStandalone, &spec.Standalone
+		case t != nil && o.Component == componentShard:
+			before, target = t.Shard.PodTemplate.Spec.Resources, &spec.Shard
+		case t != nil && o.Component == componentConfigSvr:
+			before, target = t.ConfigServer.PodTemplate.Spec.Resources, &spec.ConfigServer
+		case t != nil && o.Component == componentMongos:
+			before, target = t.Mongos.PodTemplate.Spec.Resources, &spec.Mongos
+		case t != nil:
+			return nil, nil, fmt.Errorf("--component must be one of %s, %s, %s and %s for a sharded MongoDB", componentShard, componentConfigSvr, componentMongos, componentExporter)
+		default:
+			return nil, nil, fmt.Errorf("--component must be one of %s and %s for MongoDB", componentStandalone, componentExporter)
+		}
+		after, changes := o.resourceChanges(before)
+		*target = after
+		return spec, changes, nil
+	case *api.MySQL:
+		spec := &opsapi.MySQLVerticalScalingSpec{}
+		var target **core.ResourceRequirements
+		switch o.Component {
+		case "", componentMySQL:
+			before, target = obj.Spec.PodTemplate.Spec.Resources, &spec.MySQL
+			o.Component = componentMySQL
+		case componentExporter:
+			before, target = exporterResources(obj.Spec.Monitor), &spec.Exporter
+		default:
+			return nil, nil, fmt.Errorf("--component must be one of %s and %s for MySQL", componentMySQL, componentExporter)
+		}
+		after, changes := o.resourceChanges(before)
+		*target = after
+		return spec, changes, nil
+	}
+	return nil, nil, fmt.Errorf("%s can not be scaled vertically", db.Kind)
+}
+
+// resourceChanges sets both the request and the limit of the given resources.
+func (o *ScaleOptions) resourceChanges(before core.ResourceRequirements) (*core.ResourceRequirements, []scaleChange) {
+	after := before.DeepCopy()
+	if after.Requests == nil {
+		after.Requests = core.ResourceList{}
+	}
+	if after.Limits == nil {
+		after.Limits = core.ResourceList{}
+	}
+
+	var changes []scaleChange
+	for _, r := range []struct {
+		name  core.ResourceName
+		value string
+	}{
+		{core.ResourceCPU, o.CPU},
+		{core.ResourceMemory, o.Memory},
+	} {
+		if r.value == "" {
+			continue
+		}
+		q := kresource.MustParse(r.value)
+		after.Requests[r.name], after.Limits[r.name] = q, q
+		oldValue, newValue := formatResource(before, r.name), formatResource(*after, r.name)
+		if oldValue != newValue {
+			changes = append(changes, scaleChange{o.Component, string(r.name) + " (request/limit)", oldValue, newValue})
+		}
+	}
+	return after, changes
+}
+
+func formatResource(r core.ResourceRequirements, name core.ResourceName) string {
+	quantity := func(l core.ResourceList) string {
+		if q, ok := l[name]; ok {
+			return q.String()
+		}
+		return "<none>"
+	}
+	return fmt.Sprintf("%s/%s", quantity(r.Requests), quantity(r.Limits))
+}
+
+// exporterResources returns the resources of the exporter sidecar, which moved
+// from the agent spec to the prometheus exporter spec.
+func exporterResources(monitor *mona.AgentSpec) core.ResourceRequirements {
+	if monitor == nil {
+		return core.ResourceRequirements{}
+	}
+	if p := monitor.Prometheus; p != nil && p.Exporter != nil {
+		return p.Exporter.Resources
+	}
+	return monitor.Resources
+}
@@ -0,0 +1,280 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+)
+
+const (
+	mongoDBTLSDir = "/var/run/mongodb/tls"
+	mySQLTLSDir   = "/etc/mysql/certs"
+)
+
+// dbShell is the native client of a database and the pods it can be run in.
+type dbShell struct {
+	// Selector selects the pods to run the client in, e.g. the primary.
+	Selector map[string]string
+	// Command starts the client. It is run by a shell in the database
+	// container with the user and password in the shell variables user and
+	// password, so that they never become part of the exec request or of the
+	// command line of a process in the pod.
+	Command []string
+	// Credentials is a shell script that sets the user and password
+	// variables. It takes them from the environment the database container
+	// gets from the database secret, until loadCredentials replaces it.
+	Credentials string
+	// Setup is a shell script that runs before the client, e.g. to hand the
+	// password over to it.
+	Setup string
+
+	// Secret is the database secret, if the database spec names one.
+	Secret *core.SecretVolumeSource
+}
+
+// newDBShell returns the native client of the database.
+func newDBShell(db *database) (*dbShell, error) {
+	sh := &dbShell{Selector: db.Selector}
+
+	switch obj := db.Object.(type) {
+	case *api.Postgres:
+		sh.Selector = withRole(db.Selector, "primary")
+		sh.Command = []string{"psql", `--username="$user"`, "--dbname=postgres"}
+		sh.Credentials = "user=\"${POSTGRES_USER:-postgres}\"\npassword=\"$POSTGRES_PASSWORD\"\n"
+		sh.Setup = "export PGPASSWORD=\"$password\"\n"
+		if obj.Spec.TLS != nil {
+			sh.Setup += "export PGSSLMODE=require\n"
+		}
+		sh.Secret = obj.Spec.DatabaseSecret
+	case *api.MySQL:
+		if obj.Spec.Topology != nil {
+			sh.Selector = withRole(db.Selector, "primary")
+		}
+		sh.Command = mysqlCommand(obj.Spec.TLS != nil)
+		sh.Credentials, sh.Setup = mysqlCredentials, mysqlSetup
+		sh.Secret = obj.Spec.DatabaseSecret
+	case *api.MariaDB:
+		// the MariaDB API has no TLS configuration yet
+		sh.Command = mysqlCommand(false)
+		sh.Credentials, sh.Setup = mysqlCredentials, mysqlSetup
+		sh.Secret = obj.Spec.DatabaseSecret
+	case *api.PerconaXtraDB:
+		sh.Command = mysqlCommand(obj.Spec.TLS != nil)
+		sh.Credentials, sh.Setup = mysqlCredentials, mysqlSetup
+		sh.Secret = obj.Spec.DatabaseSecret
+	case *api.MongoDB:
+		if obj.Spec.ShardTopology != nil {
+			sh.Selector = obj.MongosSelectors()
+		}
+		sh.Command = []string{"mongo", "admin", "--quiet"}
+		if obj.Spec.ReplicaSet != nil {
+			// the shell connects to the primary of the replica set, so that
+			// writes don't fail on a secondary
+			sh.Command = append(sh.Command, "--host="+obj.HostAddress())
+		}
+		if obj.Spec.TLS != nil || obj.Spec.SSLMode == api.SSLModeRequireSSL || obj.Spec.SSLMode == api.SSLModePreferSSL {
+			sh.Command = append(sh.Command,
+				"--tls",
+				"--tlsCAFile="+mongoDBTLSDir+"/ca.crt",
+				"--tlsCertificateKeyFile="+mongoDBTLSDir+"/client.pem",
+			)
+		}
+		sh.Command = append(sh.Command, `--shell "$dir/auth.js"`)
+		sh.Credentials = "user=\"${MONGO_INITDB_ROOT_USERNAME:-root}\"\npassword=\"$MONGO_INITDB_ROOT_PASSWORD\"\n"
+		sh.Setup = mongoSetup
+		sh.Secret = obj.Spec.DatabaseSecret
+	case *api.Redis:
+		sh.Command = []string{"redis-cli"}
+		if obj.Spec.Mode == api.RedisModeCluster {
+			sh.Command = append(sh.Command, "-c")
+		}
+	default:
+		return nil, fmt.Errorf("%s does not have a supported command line client", db.Kind)
+	}
+	return sh, nil
+}
+
+const (
+	mysqlCredentials = "user=\"${MYSQL_ROOT_USERNAME:-root}\"\npassword=\"$MYSQL_ROOT_PASSWORD\"\n"
+	mysqlSetup       = "export MYSQL_PWD=\"$password\"\n"
+
+	// The mongo shell only takes the password as an argument, so it is handed
+	// over in files that the shell reads and removes before authenticating.
+	mongoSetup = `umask 077
+dir=$(mktemp -d) || exit 1
+printf %s "$user" > "$dir/user"
+printf %s "$password" > "$dir/password"
+printf 'var dir = "%s"; var user = cat(dir + "/user"), password = cat(dir + "/password"); ' "$dir" > "$dir/auth.js"
+printf 'removeFile(dir + "/user"); removeFile(dir + "/password"); removeFile(dir + "/auth.js"); removeFile(dir); ' >> "$dir/auth.js"
+printf 'db.getSiblingDB("admin").auth(user, password); password = undefined;\n' >> "$dir/auth.js"
+`
+)
+
+func mysqlCommand(tls bool) []string {
+	cmd := []string{"mysql", `--user="$user"`}
+	if tls {
+		cmd = append(cmd,
+			"--ssl-ca="+mySQLTLSDir+"/ca.crt",
+			"--ssl-cert="+mySQLTLSDir+"/client.crt",
+			"--ssl-key="+mySQLTLSDir+"/client.key",
+		)
+	}
+	return cmd
+}
+
+func withRole(selector map[string]string, role string) map[string]string {
+	out := map[string]string{api.LabelRole: role}
+	for k, v := range selector {
+		out[k] = v
+	}
+	return out
+}
+
+// uploadCredentials stores a user and password in a new private directory in
+// the pod and returns the directory. They are sent over stdin, so that they
+// don't show up in the exec request.
+const uploadCredentials = `umask 077
+dir=$(mktemp -d) || exit 1
+IFS= read -r user && printf %s "$user" > "$dir/user" && cat > "$dir/password" && echo "$dir"
+`
+
+// loadCredentials makes the shell log in with the user and password of the
+// database secret, i.e. spec.databaseSecret or the secret of the AppBinding
+// of the database. They are handed over to the pod in files that the shell
+// reads and removes before it starts the client. If there is no secret or it
+// can't be read, e.g. since the user is not allowed to get secrets, the shell
+// falls back to the credentials in the environment of the database container.
+func (sh *dbShell) loadCredentials(config *rest.Config, kc kubernetes.Interface, ac appcat_cs.Interface, db *database, pod *core.Pod, container string) error {
+	if sh.Credentials == "" {
+		return nil
+	}
+
+	var secretName string
+	if sh.Secret != nil {
+		secretName = sh.Secret.SecretName
+	} else {
+		binding, err := ac.AppcatalogV1alpha1().AppBindings(db.Namespace).Get(context.TODO(), db.Name, metav1.GetOptions{})
+		if err != nil && !kerr.IsNotFound(err) && !kerr.IsForbidden(err) {
+			return err
+		}
+		if err == nil && binding.Spec.Secret != nil {
+			secretName = binding.Spec.Secret.Name
+		}
+	}
+	if secretName == "" {
+		return nil
+	}
+	secret, err := kc.CoreV1().Secrets(db.Namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if kerr.IsNotFound(err) || kerr.IsForbidden(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	user := firstKey(secret.Data, core.BasicAuthUsernameKey, "POSTGRES_USER")
+	password := firstKey(secret.Data, core.BasicAuthPasswordKey, "POSTGRES_PASSWORD")
+	if user == "" || password == "" {
+		return fmt.Errorf("secret %s/%s has no user or password", secret.Namespace, secret.Name)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = execInPod(config, kc, pod, container, []string{"sh", "-c", uploadCredentials}, remotecommand.StreamOptions{
+		Stdin:  strings.NewReader(user + "\n" + password),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hand the credentials of secret %s/%s over to pod %s: %v %s", secret.Namespace, secret.Name, pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	dir := strings.TrimSpace(stdout.String())
+	if dir == "" {
+		return fmt.Errorf("failed to hand the credentials of secret %s/%s over to pod %s", secret.Namespace, secret.Name, pod.Name)
+	}
+	sh.Credentials = fmt.Sprintf("creds=%s\nuser=$(cat \"$creds/user\")\npassword=$(cat \"$creds/password\")\nrm -rf \"$creds\"\n", shellQuote(dir))
+	return nil
+}
+
+// runningPod returns a running pod that matches the selector of the shell.
+func (sh *dbShell) runningPod(kc kubernetes.Interface, db *database) (*core.Pod, error) {
+	pods, err := kc.CoreV1().Pods(db.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(sh.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == core.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running pod of %s %s/%s matches %s", db.Kind, db.Namespace, db.Name, labels.SelectorFromSet(sh.Selector))
+}
+
+// execInPod runs the command in the container and streams stdin, stdout and
+// stderr from and to the given streams.
+func execInPod(config *rest.Config, kc kubernetes.Interface, pod *core.Pod, container string, command []string, options remotecommand.StreamOptions) error {
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+	req := kc.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec")
+	req.VersionedParams(&core.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     options.Stdin != nil,
+		Stdout:    options.Stdout != nil,
+		Stderr:    options.Stderr != nil,
+		TTY:       options.Tty,
+	}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return exec.Stream(options)
+}
+
+// command returns the shell command that runs the setup and starts the client
+// with the given arguments.
+func (sh *dbShell) command(args ...string) []string {
+	script := sh.Credentials + sh.Setup + "exec " + strings.Join(sh.Command, " ")
+	for _, arg := range args {
+		script += " " + shellQuote(arg)
+	}
+	return []string{"sh", "-c", script}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,195 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+	"kubedb.dev/cli/pkg/describer"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	upgradeLong = templates.LongDesc(`
+		Upgrade a database to another version of the KubeDB catalog. The target
+		version must exist in the catalog and must not be deprecated. The upgrade is
+		done by the KubeDB enterprise operator through an Upgrade OpsRequest, which
+		this command creates.
+    `)
+
+	upgradeExample = templates.Examples(`
+		# Upgrade a mongodb to version 4.2.3
+		kubedb upgrade mg/mongodb-demo --to 4.2.3
+
+		# Upgrade a postgres and wait until the upgrade is done
+		kubedb upgrade pg/postgres-demo --to 12.2 --watch`)
+)
+
+type UpgradeOptions struct {
+	CmdParent string
+	Namespace string
+	To        string
+	Watch     bool
+	Timeout   time.Duration
+
+	NewBuilder    func() *resource.Builder
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	BuilderArgs []string
+
+	genericclioptions.IOStreams
+}
+
+func NewCmdUpgrade(parent string, f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &UpgradeOptions{CmdParent: parent, Timeout: 30 * time.Minute, IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "upgrade (TYPE NAME | TYPE/NAME) --to VERSION",
+		Short:   i18n.T("Upgrade a database to another catalog version"),
+		Long:    upgradeLong,
+		Example: upgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		DisableFlagsInUseLine: true,
+		DisableAutoGenTag:     true,
+	}
+	cmd.Flags().StringVar(&o.To, "to", o.To, "The catalog version to upgrade to.")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "If true, watch the OpsRequest until it is Successful or Failed.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to watch the OpsRequest, zero means watch forever.")
+	return cmd
+}
+
+func (o *UpgradeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("You must specify the database to upgrade. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
+	}
+	o.BuilderArgs = args
+
+	o.KubeClient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
+	o.NewBuilder = f.NewBuilder
+
+	return nil
+}
+
+func (o *UpgradeOptions) Validate() error {
+	if o.To == "" {
+		return fmt.Errorf("--to is required")
+	}
+	return nil
+}
+
+func (o *UpgradeOptions) Run() error {
+	db, err := singleDatabase(o.NewBuilder, o.Namespace, o.BuilderArgs)
+	if err != nil {
+		return err
+	}
+	if db.Version == o.To {
+		return fmt.Errorf("%s %s/%s already runs version %s", db.Kind, db.Namespace, db.Name, o.To)
+	}
+	if err := o.validateVersion(db); err != nil {
+		return err
+	}
+
+	ops, err := createOpsRequest(o.KubeClient, o.DynamicClient, db, opsapi.OpsRequestTypeUpgrade, map[string]interface{}{
+		"upgrade": map[string]interface{}{
+			"targetVersion": o.To,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s created, upgrading %s/%s from %s to %s\n", opsRequestName(ops.Kind, ops.Name), strings.ToLower(db.Kind), db.Name, db.Version, o.To)
+
+	if !o.Watch {
+		return nil
+	}
+	_, gvr, _ := describer.OpsRequestKindFor(db.Kind)
+	return watchOpsRequest(o.DynamicClient, gvr, ops.Namespace, ops.Name, o.Timeout, o.Out)
+}
+
+// validateVersion checks the target version against the catalog.
+func (o *UpgradeOptions) validateVersion(db *database) error {
+	kind, gvr, ok := describer.VersionKindFor(db.Kind)
+	if !ok {
+		return fmt.Errorf("%s has no versions in the catalog", db.Kind)
+	}
+	v, err := describer.ResolveVersion(o.DynamicClient, db.Kind, o.To)
+	if err != nil {
+		return err
+	}
+	if v.Missing {
+		available, err := availableVersions(o.DynamicClient, gvr)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s %q does not exist in the catalog, available versions: %s", kind, o.To, orNone(strings.Join(available, ", ")))
+	}
+	if v.Deprecated {
+		return fmt.Errorf("%s %q is deprecated", kind, o.To)
+	}
+	return nil
+}
+
+// availableVersions lists the names of the catalog versions that are not
+// deprecated.
+func availableVersions(dc dynamic.Interface, gvr schema.GroupVersionResource) ([]string, error) {
+	list, err := dc.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, u := range list.Items {
+		if deprecated, _, _ := unstructured.NestedBool(u.Object, "spec", "deprecated"); !deprecated {
+			names = append(names, u.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -20,10 +20,14 @@ import (
 	"context"
 	"time"
 
+	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
+	"kmodules.xyz/client-go/discovery"
 	appcat "kmodules.xyz/custom-resources/apis/appcatalog/v1alpha1"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
 	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
@@ -32,20 +36,27 @@ const (
 	KindAppBinding string = "AppBinding"
 )
 
-type backupInvokerInfo struct {
-	name              string
-	kind              string
-	schedule          string
-	task              string
-	repository        string
-	bucket            string
-	creationTimestamp metav1.Time
+// getAppBinding returns the AppBinding of a database or nil if it has not been
+// created yet.
+func getAppBinding(c appcat_cs.Interface, namespace, name string) (*appcat.AppBinding, error) {
+	ab, err := c.AppcatalogV1alpha1().AppBindings(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if kerr.IsNotFound(err) {
+		return nil, nil
+	}
+	return ab, err
 }
 
-func showBackups(stash stash.Interface, ab *appcat.AppBinding, w describe.PrefixWriter) error {
-	w.Write(LEVEL_0, "\n")
-	w.Write(LEVEL_0, "Backup:\n")
-	var invokers []backupInvokerInfo
+// describeBackups returns the backup invokers that target the AppBinding along
+// with their BackupSessions. It returns nil if Stash is not installed.
+func describeBackups(client kubernetes.Interface, stash stash.Interface, ab *appcat.AppBinding) (*BackupDescription, error) {
+	if !discovery.ExistsGroupKind(client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
+		return nil, nil
+	}
+
+	out := &BackupDescription{}
+	if ab == nil {
+		return out, nil
+	}
 	// There could be two types of backup invokers.
 	// 1. BackupConfiguration
 	// 2. BackupBatch
@@ -53,82 +64,115 @@ func showBackups(stash stash.Interface, ab *appcat.AppBinding, w describe.Prefix
 	// Get BackupConfiguration type invokers
 	bcInvokers, err := getBackupConfigurationTypeInvokers(stash, ab)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	invokers = append(invokers, bcInvokers...)
+	out.Invokers = append(out.Invokers, bcInvokers...)
 
 	// Get BackupBatch type invokers
 	bbInvokers, err := getBackupBatchTypeInvokers(stash, ab)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	invokers = append(invokers, bbInvokers...)
+	out.Invokers = append(out.Invokers, bbInvokers...)
+
+	if len(out.Invokers) == 0 {
+		return out, nil
+	}
+
+	// Get the BackupSessions for the above invokers
+	backupSessions, err := getBackupSessions(stash, ab.Namespace, out.Invokers)
+	if err != nil {
+		return nil, err
+	}
+	for _, bs := range backupSessions {
+		out.Sessions = append(out.Sessions, BackupSessionDescription{
+			Name:              bs.Name,
+			InvokerKind:       bs.Spec.Invoker.Kind,
+			InvokerName:       bs.Spec.Invoker.Name,
+			Phase:             string(bs.Status.Phase),
+			CreationTimestamp: bs.CreationTimestamp,
+		})
+	}
+	return out, nil
+}
 
-	if len(invokers) == 0 {
+func printBackups(backups *BackupDescription, w describe.PrefixWriter) {
+	w.Write(LEVEL_0, "\n")
+	w.Write(LEVEL_0, "Backup:\n")
+	if len(backups.Invokers) == 0 {
 		w.Write(LEVEL_1, "No backup has been configured.\n")
-		return nil
+		return
 	}
 	// Print the backup invokers table
 	w.Write(LEVEL_1, "Backup Invokers:\n")
 	w.Write(LEVEL_2, "Name\tKind\tSchedule\tTask\tRepository\tBucket\tAge\n")
 	w.Write(LEVEL_2, "----\t----\t--------\t----\t----------\t------\t---\n")
-	for _, invk := range invokers {
-		age := duration.HumanDuration(time.Since(invk.creationTimestamp.Time))
-		w.Write(LEVEL_2, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", invk.name, invk.kind, invk.schedule, invk.task, invk.repository, invk.bucket, age)
+	for _, invk := range backups.Invokers {
+		age := duration.HumanDuration(time.Since(invk.CreationTimestamp.Time))
+		w.Write(LEVEL_2, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", invk.Name, invk.Kind, invk.Schedule, invk.Task, invk.Repository, invk.Bucket, age)
 	}
 
-	// Get the BackupSessions for the above invokers
-	backupSessions, err := getBackupSessions(stash, ab.Namespace, invokers)
-	if err != nil {
-		return err
-	}
 	// Print recent backup table
-	if len(backupSessions) != 0 {
+	if len(backups.Sessions) != 0 {
 		w.Write(LEVEL_1, "Recent Backups:\n")
 		w.Write(LEVEL_2, "Name\tInvoker-kind\tInvoker-name\tPhase\tAge\n")
 		w.Write(LEVEL_2, "----\t------------\t------------\t-----\t---\n")
-		for _, bs := range backupSessions {
+		for _, bs := range backups.Sessions {
 			age := duration.HumanDuration(time.Since(bs.CreationTimestamp.Time))
-			w.Write(LEVEL_2, "%s\t%s\t%s\t%s\t%s\n", bs.Name, bs.Spec.Invoker.Kind, bs.Spec.Invoker.Name, bs.Status.Phase, age)
+			w.Write(LEVEL_2, "%s\t%s\t%s\t%s\t%s\n", bs.Name, bs.InvokerKind, bs.InvokerName, bs.Phase, age)
 		}
 	}
-	return nil
 }
 
-func getBackupConfigurationTypeInvokers(stash stash.Interface, ab *appcat.AppBinding) ([]backupInvokerInfo, error) {
-	var bcInvokers []backupInvokerInfo
-	backupConfigurations, err := stash.StashV1beta1().BackupConfigurations(ab.Namespace).List(context.TODO(), metav1.ListOptions{})
+// BackupConfigurationsFor returns the BackupConfigurations that have the
+// AppBinding of a database as their target.
+func BackupConfigurationsFor(stash stash.Interface, namespace, appBinding string) ([]stashV1beta1.BackupConfiguration, error) {
+	backupConfigurations, err := stash.StashV1beta1().BackupConfigurations(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
 
 	// Identify those BackupConfigurations that has this AppBinding as target
+	var out []stashV1beta1.BackupConfiguration
 	for _, bc := range backupConfigurations.Items {
 		if bc.Spec.Target != nil &&
 			bc.Spec.Target.Ref.Kind == KindAppBinding &&
-			bc.Spec.Target.Ref.Name == ab.Name {
-			invoker := backupInvokerInfo{
-				name:              bc.Name,
-				kind:              bc.Kind,
-				schedule:          bc.Spec.Schedule,
-				task:              bc.Spec.Task.Name,
-				repository:        bc.Spec.Repository.Name,
-				creationTimestamp: bc.CreationTimestamp,
-			}
-			bucket, err := getBucket(stash, bc.Spec.Repository.Name, bc.Namespace)
-			if err != nil {
-				return nil, err
-			}
-			invoker.bucket = bucket
+			bc.Spec.Target.Ref.Name == appBinding {
+			out = append(out, bc)
+		}
+	}
+	return out, nil
+}
+
+func getBackupConfigurationTypeInvokers(stash stash.Interface, ab *appcat.AppBinding) ([]BackupInvokerDescription, error) {
+	var bcInvokers []BackupInvokerDescription
+	backupConfigurations, err := BackupConfigurationsFor(stash, ab.Namespace, ab.Name)
+	if err != nil {
+		return nil, err
+	}
 
-			bcInvokers = append(bcInvokers, invoker)
+	for _, bc := range backupConfigurations {
+		invoker := BackupInvokerDescription{
+			Name:              bc.Name,
+			Kind:              bc.Kind,
+			Schedule:          bc.Spec.Schedule,
+			Task:              bc.Spec.Task.Name,
+			Repository:        bc.Spec.Repository.Name,
+			CreationTimestamp: bc.CreationTimestamp,
 		}
+		bucket, err := getBucket(stash, bc.Spec.Repository.Name, bc.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		invoker.Bucket = bucket
+
+		bcInvokers = append(bcInvokers, invoker)
 	}
 	return bcInvokers, nil
 }
 
-func getBackupBatchTypeInvokers(stash stash.Interface, ab *appcat.AppBinding) ([]backupInvokerInfo, error) {
-	var bbInvokers []backupInvokerInfo
+func getBackupBatchTypeInvokers(stash stash.Interface, ab *appcat.AppBinding) ([]BackupInvokerDescription, error) {
+	var bbInvokers []BackupInvokerDescription
 	backupBatches, err := stash.StashV1beta1().BackupBatches(ab.Namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -138,19 +182,19 @@ func getBackupBatchTypeInvokers(stash stash.Interface, ab *appcat.AppBinding) ([
 			if m.Target != nil &&
 				m.Target.Ref.Kind == KindAppBinding &&
 				m.Target.Ref.Name == ab.Name {
-				invoker := backupInvokerInfo{
-					name:              bb.Name,
-					kind:              bb.Kind,
-					schedule:          bb.Spec.Schedule,
-					task:              m.Task.Name,
-					repository:        bb.Spec.Repository.Name,
-					creationTimestamp: bb.CreationTimestamp,
+				invoker := BackupInvokerDescription{
+					Name:              bb.Name,
+					Kind:              bb.Kind,
+					Schedule:          bb.Spec.Schedule,
+					Task:              m.Task.Name,
+					Repository:        bb.Spec.Repository.Name,
+					CreationTimestamp: bb.CreationTimestamp,
 				}
 				bucket, err := getBucket(stash, bb.Spec.Repository.Name, bb.Namespace)
 				if err != nil {
 					return nil, err
 				}
-				invoker.bucket = bucket
+				invoker.Bucket = bucket
 
 				bbInvokers = append(bbInvokers, invoker)
 			}
@@ -169,7 +213,7 @@ func getBucket(stash stash.Interface, repoName, repoNamespace string) (string, e
 	return repo.Spec.Backend.Container()
 }
 
-func getBackupSessions(stash stash.Interface, namespace string, invokers []backupInvokerInfo) ([]stashV1beta1.BackupSession, error) {
+func getBackupSessions(stash stash.Interface, namespace string, invokers []BackupInvokerDescription) ([]stashV1beta1.BackupSession, error) {
 	var backupSessions []stashV1beta1.BackupSession
 
 	bsList, err := stash.StashV1beta1().BackupSessions(namespace).List(context.TODO(), metav1.ListOptions{})
@@ -185,10 +229,10 @@ func getBackupSessions(stash stash.Interface, namespace string, invokers []backu
 	return backupSessions, nil
 }
 
-func ownByInvoker(bs stashV1beta1.BackupSession, invokers []backupInvokerInfo) bool {
+func ownByInvoker(bs stashV1beta1.BackupSession, invokers []BackupInvokerDescription) bool {
 	for i := range invokers {
-		if invokers[i].kind == bs.Spec.Invoker.Kind &&
-			invokers[i].name == bs.Spec.Invoker.Name {
+		if invokers[i].Kind == bs.Spec.Invoker.Kind &&
+			invokers[i].Name == bs.Spec.Invoker.Name {
 			return true
 		}
 	}
@@ -0,0 +1,378 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+)
+
+type catalogVersionKind struct {
+	resource     string
+	databaseKind string
+}
+
+// catalogVersionKinds maps every version kind of the catalog to its resource
+// and to the kind of the database that refers to it.
+var catalogVersionKinds = map[string]catalogVersionKind{
+	catalog.ResourceKindElasticsearchVersion: {catalog.ResourcePluralElasticsearchVersion, api.ResourceKindElasticsearch},
+	catalog.ResourceKindEtcdVersion:          {catalog.ResourcePluralEtcdVersion, api.ResourceKindEtcd},
+	catalog.ResourceKindMemcachedVersion:     {catalog.ResourcePluralMemcachedVersion, api.ResourceKindMemcached},
+	catalog.ResourceKindMongoDBVersion:       {catalog.ResourcePluralMongoDBVersion, api.ResourceKindMongoDB},
+	catalog.ResourceKindMySQLVersion:         {catalog.ResourcePluralMySQLVersion, api.ResourceKindMySQL},
+	catalog.ResourceKindPerconaXtraDBVersion: {catalog.ResourcePluralPerconaXtraDBVersion, api.ResourceKindPerconaXtraDB},
+	catalog.ResourceKindPgBouncerVersion:     {catalog.ResourcePluralPgBouncerVersion, api.ResourceKindPgBouncer},
+	catalog.ResourceKindPostgresVersion:      {catalog.ResourcePluralPostgresVersion, api.ResourceKindPostgres},
+	catalog.ResourceKindProxySQLVersion:      {catalog.ResourcePluralProxySQLVersion, api.ResourceKindProxySQL},
+	catalog.ResourceKindRedisVersion:         {catalog.ResourcePluralRedisVersion, api.ResourceKindRedis},
+}
+
+func catalogVersionResource(kind string) schema.GroupVersionResource {
+	return catalog.SchemeGroupVersion.WithResource(catalogVersionKinds[kind].resource)
+}
+
+// VersionDescriber describes any of the catalog version kinds. Like the
+// OpsRequests, they are read with the dynamic client since there is no typed
+// client for the catalog group. The versions are cluster scoped.
+type VersionDescriber struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+	kind    string
+}
+
+func (d *VersionDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	u, err := d.dynamic.Resource(catalogVersionResource(d.kind)).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	item, err := newCatalogVersion(u)
+	if err != nil {
+		return "", err
+	}
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(metav1.NamespaceAll).Search(scheme.Scheme, u)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return d.describeVersion(item, events)
+}
+
+func (d *VersionDescriber) describeVersion(item *catalogVersion, events *core.EventList) (string, error) {
+	return tabbedString(func(out io.Writer) error {
+		w := describe.NewPrefixWriter(out)
+		w.Write(LEVEL_0, "Name:\t%s\n", item.Meta.Name)
+		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.Meta.CreationTimestamp))
+		printLabelsMultiline(LEVEL_0, w, "Labels", item.Meta.Labels)
+		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Meta.Annotations)
+		w.Write(LEVEL_0, "Database:\t%s\n", catalogVersionKinds[item.Kind].databaseKind)
+		w.Write(LEVEL_0, "Version:\t%s\n", item.Description.Version)
+		w.Write(LEVEL_0, "Deprecated:\t%v\n", item.Description.Deprecated)
+		if item.Description.Deprecated {
+			w.Write(LEVEL_0, "WARNING:\tthis version is deprecated, new databases should not use it\n")
+		}
+		if item.Description.PodSecurityPolicy != "" {
+			w.Write(LEVEL_0, "Pod Security Policy:\t%s\n", item.Description.PodSecurityPolicy)
+		}
+		printVersionImages(LEVEL_0, item.Description.Images, w)
+
+		for _, s := range item.Sections {
+			w.Write(LEVEL_0, "\n")
+			printSection(LEVEL_0, s, w)
+		}
+
+		if events != nil {
+			printEvents(describeEvents(events), w)
+		}
+
+		return nil
+	})
+}
+
+// catalogVersion holds a version of any database kind. The fields that only
+// some of the kinds have are kept as Sections.
+type catalogVersion struct {
+	Kind        string
+	Meta        metav1.ObjectMeta
+	Description VersionDescription
+	Sections    []*Section
+}
+
+func newCatalogVersion(u *unstructured.Unstructured) (*catalogVersion, error) {
+	v := &catalogVersion{Kind: u.GetKind()}
+	switch v.Kind {
+	case catalog.ResourceKindElasticsearchVersion:
+		var obj catalog.ElasticsearchVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("Tools", obj.Spec.Tools.Image)
+		v.Description.addImage("InitContainer", obj.Spec.InitContainer.Image)
+		v.Description.addImage("YQ", obj.Spec.InitContainer.YQImage)
+		s := newSection("Authentication")
+		s.addField("Plugin", "%s", valueOrNone(string(obj.Spec.AuthPlugin)))
+		v.Sections = append(v.Sections, s)
+	case catalog.ResourceKindEtcdVersion:
+		var obj catalog.EtcdVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:       obj.Name,
+			Version:    obj.Spec.Version,
+			Deprecated: obj.Spec.Deprecated,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("Tools", obj.Spec.Tools.Image)
+	case catalog.ResourceKindMemcachedVersion:
+		var obj catalog.MemcachedVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+	case catalog.ResourceKindMongoDBVersion:
+		var obj catalog.MongoDBVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("Tools", obj.Spec.Tools.Image)
+		v.Description.addImage("InitContainer", obj.Spec.InitContainer.Image)
+	case catalog.ResourceKindMySQLVersion:
+		var obj catalog.MySQLVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("Tools", obj.Spec.Tools.Image)
+		v.Description.addImage("InitContainer", obj.Spec.InitContainer.Image)
+		v.Description.addImage("ReplicationModeDetector", obj.Spec.ReplicationModeDetector.Image)
+		v.Sections = append(v.Sections, describeMySQLUpgradeConstraints(obj.Spec.UpgradeConstraints))
+	case catalog.ResourceKindPerconaXtraDBVersion:
+		var obj catalog.PerconaXtraDBVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("InitContainer", obj.Spec.InitContainer.Image)
+	case catalog.ResourceKindPgBouncerVersion:
+		var obj catalog.PgBouncerVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:       obj.Name,
+			Version:    obj.Spec.Version,
+			Deprecated: obj.Spec.Deprecated,
+		}
+		v.Description.addImage("Server", obj.Spec.Server.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+	case catalog.ResourceKindPostgresVersion:
+		var obj catalog.PostgresVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+		v.Description.addImage("Tools", obj.Spec.Tools.Image)
+	case catalog.ResourceKindProxySQLVersion:
+		var obj catalog.ProxySQLVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("ProxySQL", obj.Spec.Proxysql.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+	case catalog.ResourceKindRedisVersion:
+		var obj catalog.RedisVersion
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		v.Meta = obj.ObjectMeta
+		v.Description = VersionDescription{
+			Name:              obj.Name,
+			Version:           obj.Spec.Version,
+			Deprecated:        obj.Spec.Deprecated,
+			PodSecurityPolicy: obj.Spec.PodSecurityPolicies.DatabasePolicyName,
+		}
+		v.Description.addImage("DB", obj.Spec.DB.Image)
+		v.Description.addImage("Exporter", obj.Spec.Exporter.Image)
+	default:
+		return nil, fmt.Errorf("unknown catalog version kind %q", v.Kind)
+	}
+	return v, nil
+}
+
+func describeMySQLUpgradeConstraints(c catalog.MySQLUpgradeConstraints) *Section {
+	s := newSection("Upgrade Constraints")
+	allow := s.addSection("Allowlist")
+	allow.addField("Standalone", "%s", joinOrNone(c.Allowlist.Standalone))
+	allow.addField("GroupReplication", "%s", joinOrNone(c.Allowlist.GroupReplication))
+	deny := s.addSection("Denylist")
+	deny.addField("Standalone", "%s", joinOrNone(c.Denylist.Standalone))
+	deny.addField("GroupReplication", "%s", joinOrNone(c.Denylist.GroupReplication))
+	return s
+}
+
+// VersionKindFor returns the catalog version kind of a database kind and its
+// resource.
+func VersionKindFor(databaseKind string) (string, schema.GroupVersionResource, bool) {
+	for k, v := range catalogVersionKinds {
+		if v.databaseKind == databaseKind {
+			return k, catalogVersionResource(k), true
+		}
+	}
+	return "", schema.GroupVersionResource{}, false
+}
+
+// ResolveVersion returns the catalog entry that the version of a database
+// resolves to. It returns nil if the database kind has no version kind in
+// the catalog.
+func ResolveVersion(dc dynamic.Interface, databaseKind, version string) (*VersionDescription, error) {
+	_, gvr, ok := VersionKindFor(databaseKind)
+	if !ok || version == "" {
+		return nil, nil
+	}
+
+	u, err := dc.Resource(gvr).Get(context.TODO(), version, metav1.GetOptions{})
+	if kerr.IsNotFound(err) {
+		return &VersionDescription{Name: version, Missing: true}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	v, err := newCatalogVersion(u)
+	if err != nil {
+		return nil, err
+	}
+	return &v.Description, nil
+}
+
+// describeResolvedVersion resolves the version of a database for its
+// description. The catalog is cluster scoped and often not readable by users
+// of a namespace, so an error is kept in the description instead of failing it.
+func describeResolvedVersion(dc dynamic.Interface, databaseKind, version string) *VersionDescription {
+	v, err := ResolveVersion(dc, databaseKind, version)
+	if err != nil {
+		return &VersionDescription{Name: version, Error: err.Error()}
+	}
+	return v
+}
+
+func printResolvedVersion(v *VersionDescription, w describe.PrefixWriter) {
+	w.Write(LEVEL_0, "\n")
+	w.Write(LEVEL_0, "Resolved Version:\n")
+	w.Write(LEVEL_1, "Name:\t%s\n", v.Name)
+	if v.Error != "" {
+		w.Write(LEVEL_1, "WARNING:\tcan not resolve version %s: %s\n", v.Name, v.Error)
+		return
+	}
+	if v.Missing {
+		w.Write(LEVEL_1, "WARNING:\tversion %s is not found in the catalog\n", v.Name)
+		return
+	}
+	w.Write(LEVEL_1, "Version:\t%s\n", v.Version)
+	if v.Deprecated {
+		w.Write(LEVEL_1, "WARNING:\tversion %s is DEPRECATED, consider upgrading to a supported version\n", v.Name)
+	}
+	printVersionImages(LEVEL_1, v.Images, w)
+}
+
+func printVersionImages(level int, images []VersionImage, w describe.PrefixWriter) {
+	if len(images) == 0 {
+		w.Write(level, "Images:\t%s\n", ValueNone)
+		return
+	}
+	w.Write(level, "Images:\n")
+	for _, img := range images {
+		w.Write(level+1, "%s:\t%s\n", img.Name, img.Image)
+	}
+}
@@ -26,12 +26,13 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
 	cs "kubedb.dev/apimachinery/client/clientset/versioned/typed/kubedb/v1alpha1"
 	"kubedb.dev/cli/pkg/events"
 
 	"github.com/golang/glog"
-	appsv1 "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +41,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -99,14 +101,29 @@ func describerMap(clientConfig *rest.Config) (map[schema.GroupKind]describe.Reso
 	if err != nil {
 		return nil, err
 	}
+	dc, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	m := map[schema.GroupKind]describe.ResourceDescriber{
-		api.Kind(api.ResourceKindElasticsearch): &ElasticsearchDescriber{client: c, kubedb: k, stash: s},
-		api.Kind(api.ResourceKindMemcached):     &MemcachedDescriber{client: c, kubedb: k, stash: s},
-		api.Kind(api.ResourceKindMongoDB):       &MongoDBDescriber{client: c, kubedb: k, stash: s},
-		api.Kind(api.ResourceKindMySQL):         &MySQLDescriber{client: c, kubedb: k, stash: s, appcat: appcat},
-		api.Kind(api.ResourceKindPostgres):      &PostgresDescriber{client: c, kubedb: k, stash: s},
-		api.Kind(api.ResourceKindRedis):         &RedisDescriber{client: c, kubedb: k, stash: s},
+		api.Kind(api.ResourceKindElasticsearch): &ElasticsearchDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindEtcd):          &EtcdDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindMariaDB):       &MariaDBDescriber{client: c, kubedb: k, stash: s, appcat: appcat},
+		api.Kind(api.ResourceKindMemcached):     &MemcachedDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindMongoDB):       &MongoDBDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindMySQL):         &MySQLDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindPerconaXtraDB): &PerconaXtraDBDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindPgBouncer):     &PgBouncerDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindPostgres):      &PostgresDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindProxySQL):      &ProxySQLDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+		api.Kind(api.ResourceKindRedis):         &RedisDescriber{client: c, kubedb: k, stash: s, appcat: appcat, dynamic: dc},
+	}
+	for kind := range opsRequestKinds {
+		m[opsapi.Kind(kind)] = &OpsRequestDescriber{client: c, dynamic: dc, kind: kind}
+	}
+	for kind := range catalogVersionKinds {
+		m[catalog.Kind(kind)] = &VersionDescriber{client: c, dynamic: dc, kind: kind}
 	}
 
 	return m, nil
@@ -125,26 +142,19 @@ func DescriberFor(kind schema.GroupKind, clientConfig *rest.Config) (describe.Re
 	return f, ok
 }
 
-func describeStatefulSet(ps *appsv1.StatefulSet, running, waiting, succeeded, failed int, w describe.PrefixWriter) {
+func printWorkload(wl *WorkloadDescription, w describe.PrefixWriter) {
 	w.Write(LEVEL_0, "\n")
-	w.Write(LEVEL_0, "StatefulSet:\t\n")
-	w.Write(LEVEL_1, "Name:\t%s\n", ps.ObjectMeta.Name)
-	w.Write(LEVEL_1, "CreationTimestamp:\t%s\n", timeToString(&ps.CreationTimestamp))
-	printLabelsMultiline(LEVEL_1, w, "Labels", ps.Labels)
-	printAnnotationsMultiline(LEVEL_1, w, "Annotations", ps.Annotations)
-	w.Write(LEVEL_1, "Replicas:\t%d desired | %d total\n", ps.Spec.Replicas, ps.Status.Replicas)
-	w.Write(LEVEL_1, "Pods Status:\t%d Running / %d Waiting / %d Succeeded / %d Failed\n", running, waiting, succeeded, failed)
-}
-
-func describeDeployment(d *appsv1.Deployment, running, waiting, succeeded, failed int, w describe.PrefixWriter) {
-	w.Write(LEVEL_0, "\n")
-	w.Write(LEVEL_0, "Deployment:\t\n")
-	w.Write(LEVEL_1, "Name:\t%s\n", d.ObjectMeta.Name)
-	w.Write(LEVEL_1, "CreationTimestamp:\t%s\n", timeToString(&d.CreationTimestamp))
-	printLabelsMultiline(LEVEL_1, w, "Labels", d.Labels)
-	printAnnotationsMultiline(LEVEL_1, w, "Annotations", d.Annotations)
-	w.Write(LEVEL_1, "Replicas:\t%d desired | %d updated | %d total | %d available | %d unavailable\n", *(d.Spec.Replicas), d.Status.UpdatedReplicas, d.Status.Replicas, d.Status.AvailableReplicas, d.Status.UnavailableReplicas)
-	w.Write(LEVEL_1, "Pods Status:\t%d Running / %d Waiting / %d Succeeded / %d Failed\n", running, waiting, succeeded, failed)
+	w.Write(LEVEL_0, "%s:\t\n", wl.Kind)
+	w.Write(LEVEL_1, "Name:\t%s\n", wl.Name)
+	w.Write(LEVEL_1, "CreationTimestamp:\t%s\n", timeToString(&wl.CreationTimestamp))
+	printLabelsMultiline(LEVEL_1, w, "Labels", wl.Labels)
+	printAnnotationsMultiline(LEVEL_1, w, "Annotations", wl.Annotations)
+	if wl.Kind == "Deployment" {
+		w.Write(LEVEL_1, "Replicas:\t%d desired | %d updated | %d total | %d available | %d unavailable\n", wl.DesiredReplicas, wl.UpdatedReplicas, wl.Replicas, wl.AvailableReplicas, wl.UnavailableReplicas)
+	} else {
+		w.Write(LEVEL_1, "Replicas:\t%d desired | %d total\n", wl.DesiredReplicas, wl.Replicas)
+	}
+	w.Write(LEVEL_1, "Pods Status:\t%d Running / %d Waiting / %d Succeeded / %d Failed\n", wl.Pods.Running, wl.Pods.Waiting, wl.Pods.Succeeded, wl.Pods.Failed)
 }
 
 func getPodStatusForController(c coreclient.PodInterface, selector labels.Selector) (running, waiting, succeeded, failed int, err error) {
@@ -184,33 +194,57 @@ func buildIngressString(ingress []core.LoadBalancerIngress) string {
 	return buffer.String()
 }
 
-func describeService(service *core.Service, endpoints *core.Endpoints, w describe.PrefixWriter) {
+func describeService(service *core.Service, endpoints *core.Endpoints) ServiceDescription {
 	if endpoints == nil {
 		endpoints = &core.Endpoints{}
 	}
+	out := ServiceDescription{
+		Name:           service.Name,
+		Labels:         service.Labels,
+		Annotations:    service.Annotations,
+		Type:           service.Spec.Type,
+		ClusterIP:      service.Spec.ClusterIP,
+		ExternalIPs:    service.Spec.ExternalIPs,
+		LoadBalancerIP: service.Spec.LoadBalancerIP,
+		ExternalName:   service.Spec.ExternalName,
+	}
+	if len(service.Status.LoadBalancer.Ingress) > 0 {
+		out.LoadBalancerIngress = buildIngressString(service.Status.LoadBalancer.Ingress)
+	}
+	for _, sp := range service.Spec.Ports {
+		out.Ports = append(out.Ports, ServicePortDescription{
+			Name:       sp.Name,
+			Port:       sp.Port,
+			Protocol:   sp.Protocol,
+			TargetPort: sp.TargetPort,
+			NodePort:   sp.NodePort,
+			Endpoints:  formatEndpoints(endpoints, sets.NewString(sp.Name)),
+		})
+	}
+	return out
+}
+
+func printService(service *ServiceDescription, w describe.PrefixWriter) {
 	w.Write(LEVEL_0, "\n")
 	w.Write(LEVEL_0, "Service:\t\n")
 	w.Write(LEVEL_1, "Name:\t%s\n", service.Name)
 	printLabelsMultiline(LEVEL_1, w, "Labels", service.Labels)
 	printAnnotationsMultiline(LEVEL_1, w, "Annotations", service.Annotations)
-	w.Write(LEVEL_1, "Type:\t%s\n", service.Spec.Type)
-	w.Write(LEVEL_1, "IP:\t%s\n", service.Spec.ClusterIP)
-	if len(service.Spec.ExternalIPs) > 0 {
-		w.Write(LEVEL_1, "External IPs:\t%v\n", strings.Join(service.Spec.ExternalIPs, ","))
+	w.Write(LEVEL_1, "Type:\t%s\n", service.Type)
+	w.Write(LEVEL_1, "IP:\t%s\n", service.ClusterIP)
+	if len(service.ExternalIPs) > 0 {
+		w.Write(LEVEL_1, "External IPs:\t%v\n", strings.Join(service.ExternalIPs, ","))
 	}
-	if service.Spec.LoadBalancerIP != "" {
-		w.Write(LEVEL_1, "IP:\t%s\n", service.Spec.LoadBalancerIP)
+	if service.LoadBalancerIP != "" {
+		w.Write(LEVEL_1, "IP:\t%s\n", service.LoadBalancerIP)
 	}
-	if service.Spec.ExternalName != "" {
-		w.Write(LEVEL_1, "External Name:\t%s\n", service.Spec.ExternalName)
+	if service.ExternalName != "" {
+		w.Write(LEVEL_1, "External Name:\t%s\n", service.ExternalName)
 	}
-	if len(service.Status.LoadBalancer.Ingress) > 0 {
-		list := buildIngressString(service.Status.LoadBalancer.Ingress)
-		w.Write(LEVEL_1, "LoadBalancer Ingress:\t%s\n", list)
+	if service.LoadBalancerIngress != "" {
+		w.Write(LEVEL_1, "LoadBalancer Ingress:\t%s\n", service.LoadBalancerIngress)
 	}
-	for i := range service.Spec.Ports {
-		sp := &service.Spec.Ports[i]
-
+	for _, sp := range service.Ports {
 		name := sp.Name
 		if name == "" {
 			name = "<unset>"
@@ -224,33 +258,55 @@ func describeService(service *core.Service, endpoints *core.Endpoints, w describ
 		if sp.NodePort != 0 {
 			w.Write(LEVEL_1, "NodePort:\t%s\t%d/%s\n", name, sp.NodePort, sp.Protocol)
 		}
-		w.Write(LEVEL_1, "Endpoints:\t%s\n", formatEndpoints(endpoints, sets.NewString(sp.Name)))
+		w.Write(LEVEL_1, "Endpoints:\t%s\n", sp.Endpoints)
+	}
+}
+
+// describeSecret generates information about a secret. Only the keys and the
+// size of their values are kept.
+func describeSecret(secret *core.Secret, role string) SecretDescription {
+	annotations := make(map[string]string, len(secret.Annotations))
+	for k, v := range secret.Annotations {
+		if k != meta_util.LastAppliedConfigAnnotation {
+			annotations[k] = v
+		}
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := SecretDescription{
+		Role:        role,
+		Name:        secret.Name,
+		Labels:      secret.Labels,
+		Annotations: annotations,
+		Type:        secret.Type,
+	}
+	for _, k := range keys {
+		out.Keys = append(out.Keys, SecretKey{Name: k, Size: len(secret.Data[k])})
 	}
+	return out
 }
 
-// describeSecret generates information about a secret
-func describeSecret(secret *core.Secret, prefix string, w describe.PrefixWriter) {
+func printSecret(secret *SecretDescription, w describe.PrefixWriter) {
 	w.Write(LEVEL_0, "\n")
-	if prefix == "" {
+	if secret.Role == "" {
 		w.Write(LEVEL_0, "Secret:\n")
 	} else {
-		w.Write(LEVEL_0, "%s Secret:\n", prefix)
+		w.Write(LEVEL_0, "%s Secret:\n", secret.Role)
 	}
 	w.Write(LEVEL_1, "Name:\t%s\n", secret.Name)
 	printLabelsMultiline(LEVEL_1, w, "Labels", secret.Labels)
-	skipAnnotations := sets.NewString(meta_util.LastAppliedConfigAnnotation)
-	printAnnotationsMultilineWithFilter(LEVEL_1, w, "Annotations", secret.Annotations, skipAnnotations)
+	printAnnotationsMultiline(LEVEL_1, w, "Annotations", secret.Annotations)
 
 	w.Write(LEVEL_1, "Type:\t%s\n", secret.Type)
 
 	w.Write(LEVEL_1, "Data:\n")
-	for k, v := range secret.Data {
-		switch {
-		case k == core.ServiceAccountTokenKey && secret.Type == core.SecretTypeServiceAccountToken:
-			w.Write(LEVEL_2, "%s:\t%s\n", k, string(v))
-		default:
-			w.Write(LEVEL_2, "%s:\t%d bytes\n", k, len(v))
-		}
+	for _, k := range secret.Keys {
+		w.Write(LEVEL_2, "%s:\t%d bytes\n", k.Name, k.Size)
 	}
 }
 
@@ -559,17 +615,36 @@ func printFlockerVolumeSource(flocker *core.FlockerVolumeSource, w describe.Pref
 		flocker.DatasetName, flocker.DatasetUUID)
 }
 
-func DescribeEvents(el *core.EventList, w describe.PrefixWriter) {
+func describeEvents(el *core.EventList) []EventDescription {
+	if el == nil {
+		return nil
+	}
+	sort.Sort(events.SortableEvents(el.Items))
+	out := make([]EventDescription, 0, len(el.Items))
+	for _, e := range el.Items {
+		out = append(out, EventDescription{
+			Type:           e.Type,
+			Reason:         e.Reason,
+			From:           formatEventSource(e.Source),
+			Message:        strings.TrimSpace(e.Message),
+			Count:          e.Count,
+			FirstTimestamp: e.FirstTimestamp,
+			LastTimestamp:  e.LastTimestamp,
+		})
+	}
+	return out
+}
+
+func printEvents(el []EventDescription, w describe.PrefixWriter) {
 	w.Write(LEVEL_0, "\n")
-	if len(el.Items) == 0 {
+	if len(el) == 0 {
 		w.Write(LEVEL_0, "Events:\t%s\n", ValueNone)
 		return
 	}
 	w.Flush()
-	sort.Sort(events.SortableEvents(el.Items))
 	w.Write(LEVEL_0, "Events:\n  Type\tReason\tAge\tFrom\tMessage\n")
 	w.Write(LEVEL_1, "----\t------\t----\t----\t-------\n")
-	for _, e := range el.Items {
+	for _, e := range el {
 		var interval string
 		if e.Count > 1 {
 			interval = fmt.Sprintf("%s (x%d over %s)", translateTimestamp(e.LastTimestamp), e.Count, translateTimestamp(e.FirstTimestamp))
@@ -580,8 +655,8 @@ func DescribeEvents(el *core.EventList, w describe.PrefixWriter) {
 			e.Type,
 			e.Reason,
 			interval,
-			formatEventSource(e.Source),
-			strings.TrimSpace(e.Message),
+			e.From,
+			e.Message,
 		)
 	}
 }
@@ -640,12 +715,6 @@ func tabbedString(f func(io.Writer) error) (string, error) {
 	return str, nil
 }
 
-// printAnnotationsMultilineWithFilter prints filtered multiple annotations with a proper alignment.
-//nolint:unparam
-func printAnnotationsMultilineWithFilter(level int, w describe.PrefixWriter, title string, annotations map[string]string, skip sets.String) {
-	printAnnotationsMultilineWithIndent(level, w, "", title, "\t", annotations, skip)
-}
-
 // printAnnotationsMultiline prints multiple annotations with a proper alignment.
 //nolint:unparam
 func printAnnotationsMultiline(level int, w describe.PrefixWriter, title string, annotations map[string]string) {
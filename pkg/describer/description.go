@@ -0,0 +1,305 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"fmt"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kubectl/pkg/describe"
+	appcat "kmodules.xyz/custom-resources/apis/appcatalog/v1alpha1"
+	mona "kmodules.xyz/monitoring-agent-api/api/v1"
+)
+
+// DatabaseDescriber generates a DatabaseDescription for a KubeDB database.
+// Describe renders the same description as text.
+type DatabaseDescriber interface {
+	describe.ResourceDescriber
+	DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error)
+}
+
+var (
+	_ DatabaseDescriber = &ElasticsearchDescriber{}
+	_ DatabaseDescriber = &EtcdDescriber{}
+	_ DatabaseDescriber = &MariaDBDescriber{}
+	_ DatabaseDescriber = &MemcachedDescriber{}
+	_ DatabaseDescriber = &MongoDBDescriber{}
+	_ DatabaseDescriber = &MySQLDescriber{}
+	_ DatabaseDescriber = &PerconaXtraDBDescriber{}
+	_ DatabaseDescriber = &PgBouncerDescriber{}
+	_ DatabaseDescriber = &PostgresDescriber{}
+	_ DatabaseDescriber = &ProxySQLDescriber{}
+	_ DatabaseDescriber = &RedisDescriber{}
+)
+
+// DatabaseDescription is the structured description of a KubeDB database.
+// Every output format of describe is rendered from it.
+type DatabaseDescription struct {
+	Kind              string            `json:"kind"`
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	CreationTimestamp metav1.Time       `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+
+	Spec   SpecSummary   `json:"spec"`
+	Status StatusSummary `json:"status"`
+
+	// ResolvedVersion is the catalog entry that Spec.Version refers to.
+	ResolvedVersion *VersionDescription `json:"resolvedVersion,omitempty"`
+
+	Workloads []WorkloadDescription `json:"workloads,omitempty"`
+	Services  []ServiceDescription  `json:"services,omitempty"`
+	Secrets   []SecretDescription   `json:"secrets,omitempty"`
+	Topology  []MemberDescription   `json:"topology,omitempty"`
+
+	// Sections hold the details that are specific to a database kind,
+	// e.g. the sharding layout of a MongoDB or the TLS certificates.
+	Sections []*Section `json:"sections,omitempty"`
+
+	Monitor    *mona.AgentSpec    `json:"monitor,omitempty"`
+	Init       *api.InitSpec      `json:"init,omitempty"`
+	Backups    *BackupDescription `json:"backups,omitempty"`
+	AppBinding *appcat.AppBinding `json:"appBinding,omitempty"`
+
+	// OpsRequests is nil when the ops.kubedb.com group is not available.
+	OpsRequests []OpsRequestDescription `json:"opsRequests,omitempty"`
+	// OpsRequestsError is set when the OpsRequests could not be listed.
+	OpsRequestsError string `json:"opsRequestsError,omitempty"`
+
+	// Events is nil when events were not requested.
+	Events []EventDescription `json:"events,omitempty"`
+}
+
+// DatabaseDescriptionList is used to print more than one description.
+type DatabaseDescriptionList struct {
+	Items []*DatabaseDescription `json:"items"`
+}
+
+type SpecSummary struct {
+	Version           string                          `json:"version,omitempty"`
+	Mode              string                          `json:"mode,omitempty"`
+	Replicas          *int32                          `json:"replicas,omitempty"`
+	StorageType       api.StorageType                 `json:"storageType,omitempty"`
+	Storage           *core.PersistentVolumeClaimSpec `json:"storage,omitempty"`
+	Paused            bool                            `json:"paused"`
+	Halted            *bool                           `json:"halted,omitempty"`
+	TerminationPolicy api.TerminationPolicy           `json:"terminationPolicy,omitempty"`
+}
+
+// VersionDescription describes a version from the KubeDB catalog.
+type VersionDescription struct {
+	Name              string         `json:"name"`
+	Version           string         `json:"version,omitempty"`
+	Deprecated        bool           `json:"deprecated"`
+	Missing           bool           `json:"missing,omitempty"`
+	Error             string         `json:"error,omitempty"`
+	PodSecurityPolicy string         `json:"podSecurityPolicy,omitempty"`
+	Images            []VersionImage `json:"images,omitempty"`
+}
+
+type VersionImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type StatusSummary struct {
+	Phase  api.DatabasePhase `json:"phase,omitempty"`
+	Reason string            `json:"reason,omitempty"`
+}
+
+type WorkloadDescription struct {
+	Kind                string            `json:"kind"`
+	Name                string            `json:"name"`
+	CreationTimestamp   metav1.Time       `json:"creationTimestamp"`
+	Labels              map[string]string `json:"labels,omitempty"`
+	Annotations         map[string]string `json:"annotations,omitempty"`
+	DesiredReplicas     int32             `json:"desiredReplicas"`
+	Replicas            int32             `json:"replicas"`
+	UpdatedReplicas     int32             `json:"updatedReplicas,omitempty"`
+	AvailableReplicas   int32             `json:"availableReplicas,omitempty"`
+	UnavailableReplicas int32             `json:"unavailableReplicas,omitempty"`
+	Pods                PodCounts         `json:"pods"`
+}
+
+type PodCounts struct {
+	Running   int `json:"running"`
+	Waiting   int `json:"waiting"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+type ServiceDescription struct {
+	Name                string                   `json:"name"`
+	Labels              map[string]string        `json:"labels,omitempty"`
+	Annotations         map[string]string        `json:"annotations,omitempty"`
+	Type                core.ServiceType         `json:"type"`
+	ClusterIP           string                   `json:"clusterIP,omitempty"`
+	ExternalIPs         []string                 `json:"externalIPs,omitempty"`
+	LoadBalancerIP      string                   `json:"loadBalancerIP,omitempty"`
+	ExternalName        string                   `json:"externalName,omitempty"`
+	LoadBalancerIngress string                   `json:"loadBalancerIngress,omitempty"`
+	Ports               []ServicePortDescription `json:"ports,omitempty"`
+}
+
+type ServicePortDescription struct {
+	Name       string             `json:"name,omitempty"`
+	Port       int32              `json:"port"`
+	Protocol   core.Protocol      `json:"protocol"`
+	TargetPort intstr.IntOrString `json:"targetPort"`
+	NodePort   int32              `json:"nodePort,omitempty"`
+	Endpoints  string             `json:"endpoints"`
+}
+
+// SecretDescription describes a secret used by a database. Only the keys and
+// the size of their values are recorded, never the values themselves.
+type SecretDescription struct {
+	Role        string            `json:"role"`
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Type        core.SecretType   `json:"type"`
+	Keys        []SecretKey       `json:"keys,omitempty"`
+}
+
+type SecretKey struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// MemberDescription describes a pod of a database along with the role it
+// plays in the database topology.
+type MemberDescription struct {
+	Pod       string        `json:"pod"`
+	Role      string        `json:"role"`
+	Ready     bool          `json:"ready"`
+	StartTime *metav1.Time  `json:"startTime,omitempty"`
+	Phase     core.PodPhase `json:"phase"`
+}
+
+type BackupDescription struct {
+	Invokers []BackupInvokerDescription `json:"invokers,omitempty"`
+	Sessions []BackupSessionDescription `json:"sessions,omitempty"`
+}
+
+type BackupInvokerDescription struct {
+	Name              string      `json:"name"`
+	Kind              string      `json:"kind"`
+	Schedule          string      `json:"schedule"`
+	Task              string      `json:"task"`
+	Repository        string      `json:"repository"`
+	Bucket            string      `json:"bucket"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+type BackupSessionDescription struct {
+	Name              string      `json:"name"`
+	InvokerKind       string      `json:"invokerKind"`
+	InvokerName       string      `json:"invokerName"`
+	Phase             string      `json:"phase"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+type OpsRequestDescription struct {
+	Name              string                 `json:"name"`
+	Type              opsapi.OpsRequestType  `json:"type"`
+	Phase             opsapi.OpsRequestPhase `json:"phase"`
+	CreationTimestamp metav1.Time            `json:"creationTimestamp"`
+}
+
+type EventDescription struct {
+	Type           string      `json:"type"`
+	Reason         string      `json:"reason"`
+	From           string      `json:"from"`
+	Message        string      `json:"message"`
+	Count          int32       `json:"count"`
+	FirstTimestamp metav1.Time `json:"firstTimestamp"`
+	LastTimestamp  metav1.Time `json:"lastTimestamp"`
+}
+
+// Section is a titled group of fields, an optional table and nested sections.
+type Section struct {
+	Title    string     `json:"title"`
+	Fields   []Field    `json:"fields,omitempty"`
+	Table    *Table     `json:"table,omitempty"`
+	Sections []*Section `json:"sections,omitempty"`
+}
+
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Table struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows,omitempty"`
+}
+
+func newDatabaseDescription(kind string, meta metav1.ObjectMeta) *DatabaseDescription {
+	return &DatabaseDescription{
+		Kind:              kind,
+		Name:              meta.Name,
+		Namespace:         meta.Namespace,
+		CreationTimestamp: meta.CreationTimestamp,
+		Labels:            meta.Labels,
+		Annotations:       meta.Annotations,
+	}
+}
+
+func (v *VersionDescription) addImage(name, image string) {
+	if image != "" {
+		v.Images = append(v.Images, VersionImage{Name: name, Image: image})
+	}
+}
+
+func (d *DatabaseDescription) addSection(s *Section) {
+	if s != nil {
+		d.Sections = append(d.Sections, s)
+	}
+}
+
+func newSection(title string) *Section {
+	return &Section{Title: title}
+}
+
+func (s *Section) addField(name, format string, args ...interface{}) {
+	s.Fields = append(s.Fields, Field{Name: name, Value: fmt.Sprintf(format, args...)})
+}
+
+func (s *Section) addSection(title string) *Section {
+	child := newSection(title)
+	s.Sections = append(s.Sections, child)
+	return child
+}
+
+func (s *Section) setTable(columns ...string) *Table {
+	s.Table = &Table{Columns: columns}
+	return s.Table
+}
+
+func (t *Table) addRow(values ...interface{}) {
+	row := make([]string, 0, len(values))
+	for _, v := range values {
+		row = append(row, fmt.Sprint(v))
+	}
+	t.Rows = append(t.Rows, row)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/appscode/go/types"
+	"k8s.io/kubectl/pkg/describe"
+)
+
+// printDescription renders a DatabaseDescription as human readable text.
+func printDescription(desc *DatabaseDescription) (string, error) {
+	return tabbedString(func(out io.Writer) error {
+		w := describe.NewPrefixWriter(out)
+		w.Write(LEVEL_0, "Name:\t%s\n", desc.Name)
+		w.Write(LEVEL_0, "Namespace:\t%s\n", desc.Namespace)
+		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&desc.CreationTimestamp))
+		printLabelsMultiline(LEVEL_0, w, "Labels", desc.Labels)
+		printAnnotationsMultiline(LEVEL_0, w, "Annotations", desc.Annotations)
+
+		spec := desc.Spec
+		if spec.Replicas != nil {
+			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(spec.Replicas))
+		}
+		w.Write(LEVEL_0, "Status:\t%s\n", string(desc.Status.Phase))
+		if len(desc.Status.Reason) > 0 {
+			w.Write(LEVEL_0, "Reason:\t%s\n", desc.Status.Reason)
+		}
+		if spec.Version != "" {
+			if desc.ResolvedVersion != nil && desc.ResolvedVersion.Deprecated {
+				w.Write(LEVEL_0, "Version:\t%s (DEPRECATED)\n", spec.Version)
+			} else {
+				w.Write(LEVEL_0, "Version:\t%s\n", spec.Version)
+			}
+		}
+		if spec.Mode != "" {
+			w.Write(LEVEL_0, "Mode:\t%s\n", spec.Mode)
+		}
+		if spec.StorageType != "" || spec.Storage != nil {
+			describeStorage(spec.StorageType, spec.Storage, w)
+		}
+		w.Write(LEVEL_0, "Paused:\t%v\n", spec.Paused)
+		if spec.Halted != nil {
+			w.Write(LEVEL_0, "Halted:\t%v\n", *spec.Halted)
+		}
+		if spec.TerminationPolicy != "" {
+			w.Write(LEVEL_0, "Termination Policy:\t%v\n", spec.TerminationPolicy)
+		}
+
+		if desc.ResolvedVersion != nil {
+			printResolvedVersion(desc.ResolvedVersion, w)
+		}
+
+		for i := range desc.Workloads {
+			printWorkload(&desc.Workloads[i], w)
+		}
+		for i := range desc.Services {
+			printService(&desc.Services[i], w)
+		}
+		for i := range desc.Secrets {
+			printSecret(&desc.Secrets[i], w)
+		}
+
+		if desc.Topology != nil {
+			printTopology(desc.Topology, w)
+		}
+
+		for _, s := range desc.Sections {
+			w.Write(LEVEL_0, "\n")
+			printSection(LEVEL_0, s, w)
+		}
+
+		describeMonitor(desc.Monitor, w)
+
+		// Show initialization information
+		describeInitialization(desc.Init, w)
+
+		// Show Backup information
+		if desc.Backups != nil {
+			printBackups(desc.Backups, w)
+		}
+
+		// Show AppBinding
+		if desc.AppBinding != nil {
+			err := showAppBinding(desc.AppBinding, w)
+			if err != nil {
+				return err
+			}
+		}
+
+		if desc.OpsRequests != nil || desc.OpsRequestsError != "" {
+			printOpsRequests(desc.OpsRequests, desc.OpsRequestsError, w)
+		}
+
+		if desc.Events != nil {
+			printEvents(desc.Events, w)
+		}
+
+		return nil
+	})
+}
+
+func printTopology(members []MemberDescription, w describe.PrefixWriter) {
+	w.Write(LEVEL_0, "\n")
+	w.Write(LEVEL_0, "Topology:\n")
+	w.Write(LEVEL_1, "Role\tPod\tReady\tStartTime\tPhase\n")
+	w.Write(LEVEL_1, "----\t---\t-----\t---------\t-----\n")
+	for _, m := range members {
+		w.Write(LEVEL_1, "%s\t%s\t%v\t%s\t%s\n",
+			valueOrNone(m.Role),
+			m.Pod,
+			m.Ready,
+			m.StartTime,
+			m.Phase,
+		)
+	}
+}
+
+func printSection(level int, s *Section, w describe.PrefixWriter) {
+	if len(s.Fields) == 0 && s.Table == nil && len(s.Sections) == 0 {
+		w.Write(level, "%s:\t%s\n", s.Title, ValueNone)
+		return
+	}
+
+	w.Write(level, "%s:\n", s.Title)
+	for _, f := range s.Fields {
+		w.Write(level+1, "%s:\t%s\n", f.Name, f.Value)
+	}
+	if s.Table != nil {
+		printTable(level+1, s.Table, w)
+	}
+	for _, child := range s.Sections {
+		printSection(level+1, child, w)
+	}
+}
+
+func printTable(level int, t *Table, w describe.PrefixWriter) {
+	dashes := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		dashes = append(dashes, strings.Repeat("-", len(c)))
+	}
+	w.Write(level, "%s\n", strings.Join(t.Columns, "\t"))
+	w.Write(level, "%s\n", strings.Join(dashes, "\t"))
+	for _, row := range t.Rows {
+		w.Write(level, "%s\n", strings.Join(row, "\t"))
+	}
+}
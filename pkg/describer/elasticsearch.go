@@ -18,7 +18,7 @@ package describer
 
 import (
 	"context"
-	"io"
+	"fmt"
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
@@ -26,29 +26,36 @@ import (
 
 	"github.com/appscode/go/types"
 	core "k8s.io/api/core/v1"
-	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
-	"kmodules.xyz/client-go/discovery"
 	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
-	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
 
 type ElasticsearchDescriber struct {
-	client kubernetes.Interface
-	kubedb cs.KubedbV1alpha1Interface
-	stash  stash.Interface
-	appcat appcat_cs.Interface
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
 }
 
 func (d *ElasticsearchDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
-	item, err := d.kubedb.Elasticsearches(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
 	if err != nil {
 		return "", err
 	}
+	return printDescription(desc)
+}
+
+func (d *ElasticsearchDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.Elasticsearches(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	selector := labels.SelectorFromSet(item.OffshootSelectors())
 
@@ -56,86 +63,152 @@ func (d *ElasticsearchDescriber) Describe(namespace, name string, describerSetti
 	if describerSettings.ShowEvents {
 		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	return d.describeElasticsearch(item, selector, events)
 }
 
-func (d *ElasticsearchDescriber) describeElasticsearch(item *api.Elasticsearch, selector labels.Selector, events *core.EventList) (string, error) {
-	return tabbedString(func(out io.Writer) error {
-		w := describe.NewPrefixWriter(out)
-		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
-		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
-		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
-		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
-		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
-		w.Write(LEVEL_0, "Status:\t%s\n", string(item.Status.Phase))
-		if len(item.Status.Reason) > 0 {
-			w.Write(LEVEL_0, "Reason:\t%s\n", item.Status.Reason)
-		}
+func (d *ElasticsearchDescriber) describeElasticsearch(item *api.Elasticsearch, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindElasticsearch, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
 
-		if item.Spec.Replicas != nil {
-			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(item.Spec.Replicas))
-		}
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindElasticsearch, string(item.Spec.Version))
 
-		describeStorage(item.Spec.StorageType, item.Spec.Storage, w)
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
 
-		w.Write(LEVEL_0, "Paused:\t%v\n", item.Spec.Paused)
-		w.Write(LEVEL_0, "Halted:\t%v\n", item.Spec.Halted)
-		w.Write(LEVEL_0, "Termination Policy:\t%v\n", item.Spec.TerminationPolicy)
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	if item.Spec.CertificateSecret != nil {
+		secretVolumes["Certificate"] = item.Spec.CertificateSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
 
-		showWorkload(d.client, item.Namespace, selector, w)
+	desc.addSection(describeElasticsearchTopology(d.client, item))
 
-		secretVolumes := make(map[string]*core.SecretVolumeSource)
-		if item.Spec.DatabaseSecret != nil {
-			secretVolumes["Database"] = item.Spec.DatabaseSecret
-		}
-		if item.Spec.CertificateSecret != nil {
-			secretVolumes["Certificate"] = item.Spec.CertificateSecret
-		}
-		showSecret(d.client, item.Namespace, secretVolumes, w)
+	desc.addSection(describeElasticsearchTLS(d.client, item))
+
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
+
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
 
-		specific := map[string]labels.Selector{
-			"master": labels.SelectorFromSet(map[string]string{"node.role.master": "set"}),
-			"client": labels.SelectorFromSet(map[string]string{"node.role.client": "set"}),
-			"data":   labels.SelectorFromSet(map[string]string{"node.role.data": "set"}),
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindElasticsearch, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+type elasticsearchNode struct {
+	role        string
+	statefulSet string
+	replicas    int32
+	spec        *api.ElasticsearchNode
+}
+
+// elasticsearchNodes returns the node groups an Elasticsearch is expected to run.
+// A dedicated topology runs one StatefulSet per node type, named after the node
+// prefix. Otherwise a single StatefulSet runs nodes that take every role.
+func elasticsearchNodes(item *api.Elasticsearch) []elasticsearchNode {
+	topology := item.Spec.Topology
+	if topology == nil {
+		return []elasticsearchNode{
+			{
+				role:        "master|data|client",
+				statefulSet: item.OffshootName(),
+				replicas:    types.Int32(item.Spec.Replicas),
+			},
 		}
-		showTopology(d.client, item.Namespace, selector, specific, w)
+	}
 
-		if item.Spec.Monitor != nil {
-			describeMonitor(item.Spec.Monitor, w)
+	node := func(role string, spec *api.ElasticsearchNode) elasticsearchNode {
+		return elasticsearchNode{
+			role:        role,
+			statefulSet: ElasticsearchStatefulSetName(item, spec),
+			replicas:    types.Int32(spec.Replicas),
+			spec:        spec,
 		}
+	}
+	return []elasticsearchNode{
+		node("master", &topology.Master),
+		node("data", &topology.Data),
+		node("client", &topology.Client),
+	}
+}
 
-		// Show Initialization information
-		describeInitialization(item.Spec.Init, w)
+// ElasticsearchStatefulSetName returns the name of the StatefulSet the operator
+// creates for a node type of a dedicated topology. The prefix of the node is not
+// defaulted, without one the StatefulSet is named like the database.
+func ElasticsearchStatefulSetName(item *api.Elasticsearch, node *api.ElasticsearchNode) string {
+	if node.Prefix == "" {
+		return item.OffshootName()
+	}
+	return fmt.Sprintf("%s-%s", node.Prefix, item.OffshootName())
+}
 
-		ab, err := d.appcat.AppcatalogV1alpha1().AppBindings(item.Namespace).Get(context.TODO(), item.Name, metav1.GetOptions{})
-		if err != nil && !kerr.IsNotFound(err) {
-			return err
-		}
+// describeElasticsearchTopology describes the desired node layout from the spec
+// and compares it with the pods that are actually running for each node type.
+func describeElasticsearchTopology(client kubernetes.Interface, item *api.Elasticsearch) *Section {
+	nodes := elasticsearchNodes(item)
 
-		// Show Backup information
-		if discovery.ExistsGroupKind(d.client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
-			err = showBackups(d.stash, ab, w)
-			if err != nil {
-				return err
-			}
+	s := newSection("Topology")
+	for _, node := range nodes {
+		if node.spec == nil {
+			continue
 		}
+		n := s.addSection(smartLabelFor(node.role))
+		n.addField("Replicas", "%d", node.replicas)
+		n.addField("Prefix", "%s", valueOrNone(node.spec.Prefix))
+		addVolumeClaim(n, node.spec.Storage)
+		addResources(n, node.spec.Resources)
+	}
 
-		// Show AppBinding
-		if ab != nil {
-			err = showAppBinding(ab, w)
-			if err != nil {
-				return err
+	t := s.addSection("Nodes").setTable("Role", "StatefulSet", "Desired", "Running", "Status")
+	for _, node := range nodes {
+		running := 0
+		sts, err := client.AppsV1().StatefulSets(item.Namespace).Get(context.TODO(), node.statefulSet, metav1.GetOptions{})
+		if err == nil {
+			if selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector); err == nil {
+				running, _, _, _, _ = getPodStatusForController(client.CoreV1().Pods(item.Namespace), selector)
 			}
 		}
-
-		if events != nil {
-			DescribeEvents(events, w)
+		status := "OK"
+		if int32(running) != node.replicas {
+			status = "Mismatch"
 		}
+		t.addRow(node.role, node.statefulSet, node.replicas, running, status)
+	}
+	return s
+}
 
-		return nil
-	})
+func describeElasticsearchTLS(client kubernetes.Interface, item *api.Elasticsearch) *Section {
+	s := newSection("TLS")
+	s.addField("Enable SSL", "%v", item.Spec.EnableSSL)
+	var secrets []string
+	if item.Spec.CertificateSecret != nil {
+		secrets = append(secrets, item.Spec.CertificateSecret.SecretName)
+	}
+	addCertificates(s, client, item.Namespace, secrets, false)
+	return s
 }
@@ -0,0 +1,160 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+	cs "kubedb.dev/apimachinery/client/clientset/versioned/typed/kubedb/v1alpha1"
+
+	"github.com/appscode/go/types"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
+)
+
+type EtcdDescriber struct {
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
+}
+
+func (d *EtcdDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
+	if err != nil {
+		return "", err
+	}
+	return printDescription(desc)
+}
+
+func (d *EtcdDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.Etcds(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(item.OffshootSelectors())
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d.describeEtcd(item, selector, events)
+}
+
+func (d *EtcdDescriber) describeEtcd(item *api.Etcd, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindEtcd, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
+
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindEtcd, string(item.Spec.Version))
+
+	desc.addSection(describeTLSPolicy(d.client, item.Namespace, item.Spec.TLS))
+
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
+
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	if item.Spec.TLS != nil {
+		if item.Spec.TLS.Member != nil {
+			if item.Spec.TLS.Member.PeerSecret != "" {
+				secretVolumes["Peer"] = &core.SecretVolumeSource{SecretName: item.Spec.TLS.Member.PeerSecret}
+			}
+			if item.Spec.TLS.Member.ServerSecret != "" {
+				secretVolumes["Server"] = &core.SecretVolumeSource{SecretName: item.Spec.TLS.Member.ServerSecret}
+			}
+		}
+		if item.Spec.TLS.OperatorSecret != "" {
+			secretVolumes["Operator"] = &core.SecretVolumeSource{SecretName: item.Spec.TLS.OperatorSecret}
+		}
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
+
+	// Every etcd pod is a cluster member, there is no role label to split them further.
+	specific := map[string]labels.Selector{
+		"member": selector,
+	}
+	desc.Topology = describeTopology(d.client, item.Namespace, selector, specific)
+
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
+
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindEtcd, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+func describeTLSPolicy(client kubernetes.Interface, namespace string, tls *api.TLSPolicy) *Section {
+	s := newSection("TLS Policy")
+	if tls == nil {
+		return s
+	}
+
+	var secrets []string
+	if tls.Member != nil {
+		m := s.addSection("Member")
+		m.addField("Peer Secret", "%s", valueOrNone(tls.Member.PeerSecret))
+		m.addField("Server Secret", "%s", valueOrNone(tls.Member.ServerSecret))
+		if tls.Member.PeerSecret != "" {
+			secrets = append(secrets, tls.Member.PeerSecret)
+		}
+		if tls.Member.ServerSecret != "" {
+			secrets = append(secrets, tls.Member.ServerSecret)
+		}
+	}
+	s.addField("Operator Secret", "%s", valueOrNone(tls.OperatorSecret))
+	if tls.OperatorSecret != "" {
+		secrets = append(secrets, tls.OperatorSecret)
+	}
+	addCertificates(s, client, namespace, secrets, false)
+	return s
+}
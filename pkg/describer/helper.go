@@ -25,6 +25,7 @@ import (
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 
+	"github.com/appscode/go/types"
 	"github.com/fatih/camelcase"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -44,32 +45,99 @@ func describeStorage(st api.StorageType, pvcSpec *core.PersistentVolumeClaimSpec
 	} else {
 		w.Write(LEVEL_0, "StorageType:\t%s\n", api.StorageTypeDurable)
 	}
+	describeVolumeClaim(LEVEL_0, pvcSpec, w)
+}
+
+func describeVolumeClaim(level int, pvcSpec *core.PersistentVolumeClaimSpec, w describe.PrefixWriter) {
 	if pvcSpec == nil {
-		w.Write(LEVEL_0, "No volumes.\n")
+		w.Write(level, "No volumes.\n")
 		return
 	}
 
 	accessModes := getAccessModesAsString(pvcSpec.AccessModes)
 	val := pvcSpec.Resources.Requests[core.ResourceStorage]
 	capacity := val.String()
-	w.Write(LEVEL_0, "Volume:\n")
+	w.Write(level, "Volume:\n")
 	if pvcSpec.StorageClassName != nil {
-		w.Write(LEVEL_1, "StorageClass:\t%s\n", *pvcSpec.StorageClassName)
+		w.Write(level+1, "StorageClass:\t%s\n", *pvcSpec.StorageClassName)
 	}
-	w.Write(LEVEL_1, "Capacity:\t%s\n", capacity)
+	w.Write(level+1, "Capacity:\t%s\n", capacity)
 	if accessModes != "" {
-		w.Write(LEVEL_1, "Access Modes:\t%s\n", accessModes)
+		w.Write(level+1, "Access Modes:\t%s\n", accessModes)
 	}
 }
 
-func describeArchiver(archiver *api.PostgresArchiverSpec, w describe.PrefixWriter) {
-	if archiver == nil {
+// addVolumeClaim adds the storage class, capacity and access modes of a volume
+// claim as a "Volume" section.
+func addVolumeClaim(s *Section, pvcSpec *core.PersistentVolumeClaimSpec) {
+	v := s.addSection("Volume")
+	if pvcSpec == nil {
 		return
 	}
-	w.WriteLine("Archiver:")
+
+	if pvcSpec.StorageClassName != nil {
+		v.addField("StorageClass", "%s", *pvcSpec.StorageClassName)
+	}
+	capacity := pvcSpec.Resources.Requests[core.ResourceStorage]
+	v.addField("Capacity", "%s", capacity.String())
+	if accessModes := getAccessModesAsString(pvcSpec.AccessModes); accessModes != "" {
+		v.addField("Access Modes", "%s", accessModes)
+	}
+}
+
+func addResources(s *Section, resources core.ResourceRequirements) {
+	r := s.addSection("Resources")
+	if len(resources.Requests) > 0 {
+		addResourceList(r.addSection("Requests"), resources.Requests)
+	}
+	if len(resources.Limits) > 0 {
+		addResourceList(r.addSection("Limits"), resources.Limits)
+	}
+}
+
+func addResourceList(s *Section, list core.ResourceList) {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		quantity := list[core.ResourceName(name)]
+		s.addField(name, "%s", quantity.String())
+	}
+}
+
+func describeArchiver(archiver *api.PostgresArchiverSpec) *Section {
+	if archiver == nil {
+		return nil
+	}
+	s := newSection("Archiver")
 	if archiver.Storage != nil {
-		describeSnapshotStorage(LEVEL_1, *archiver.Storage, w)
+		snapshot := archiver.Storage
+		switch {
+		case snapshot.Local != nil:
+			s.addField("Type", "Local")
+			s.addField("path", "%v", snapshot.Local.MountPath)
+		case snapshot.S3 != nil:
+			s.addField("Type", "S3")
+			s.addField("endpoint", "%v", snapshot.S3.Endpoint)
+			s.addField("bucket", "%v", snapshot.S3.Bucket)
+			s.addField("prefix", "%v", snapshot.S3.Prefix)
+		case snapshot.GCS != nil:
+			s.addField("Type", "GCS")
+			s.addField("bucket", "%v", snapshot.GCS.Bucket)
+			s.addField("prefix", "%v", snapshot.GCS.Prefix)
+		case snapshot.Azure != nil:
+			s.addField("Type", "Azure")
+			s.addField("container", "%v", snapshot.Azure.Container)
+			s.addField("prefix", "%v", snapshot.Azure.Prefix)
+		case snapshot.Swift != nil:
+			s.addField("Type", "Swift")
+			s.addField("container", "%v", snapshot.Swift.Container)
+			s.addField("prefix", "%v", snapshot.Swift.Prefix)
+		}
 	}
+	return s
 }
 
 func describeInitialization(init *api.InitSpec, w describe.PrefixWriter) {
@@ -238,10 +306,11 @@ func smartLabelFor(field string) string {
 	return strings.Join(result, " ")
 }
 
-func showWorkload(client kubernetes.Interface, namespace string, selector labels.Selector, w describe.PrefixWriter) {
+func describeWorkloads(client kubernetes.Interface, namespace string, selector labels.Selector) ([]WorkloadDescription, []ServiceDescription) {
 	pc := client.CoreV1().Pods(namespace)
 	opts := metav1.ListOptions{LabelSelector: selector.String()}
 
+	var workloads []WorkloadDescription
 	if statefulSets, err := client.AppsV1().StatefulSets(namespace).List(context.TODO(), opts); err == nil {
 		for _, s := range statefulSets.Items {
 			selector, err := metav1.LabelSelectorAsSelector(s.Spec.Selector)
@@ -254,7 +323,16 @@ func showWorkload(client kubernetes.Interface, namespace string, selector labels
 				continue
 			}
 
-			describeStatefulSet(&s, running, waiting, succeeded, failed, w)
+			workloads = append(workloads, WorkloadDescription{
+				Kind:              "StatefulSet",
+				Name:              s.Name,
+				CreationTimestamp: s.CreationTimestamp,
+				Labels:            s.Labels,
+				Annotations:       s.Annotations,
+				DesiredReplicas:   types.Int32(s.Spec.Replicas),
+				Replicas:          s.Status.Replicas,
+				Pods:              PodCounts{Running: running, Waiting: waiting, Succeeded: succeeded, Failed: failed},
+			})
 		}
 	}
 
@@ -270,56 +348,126 @@ func showWorkload(client kubernetes.Interface, namespace string, selector labels
 				continue
 			}
 
-			describeDeployment(&d, running, waiting, succeeded, failed, w)
+			workloads = append(workloads, WorkloadDescription{
+				Kind:                "Deployment",
+				Name:                d.Name,
+				CreationTimestamp:   d.CreationTimestamp,
+				Labels:              d.Labels,
+				Annotations:         d.Annotations,
+				DesiredReplicas:     types.Int32(d.Spec.Replicas),
+				Replicas:            d.Status.Replicas,
+				UpdatedReplicas:     d.Status.UpdatedReplicas,
+				AvailableReplicas:   d.Status.AvailableReplicas,
+				UnavailableReplicas: d.Status.UnavailableReplicas,
+				Pods:                PodCounts{Running: running, Waiting: waiting, Succeeded: succeeded, Failed: failed},
+			})
 		}
 	}
 
-	if services, err := client.CoreV1().Services(namespace).List(context.TODO(), opts); err == nil {
-		for _, s := range services.Items {
-			endpoints, _ := client.CoreV1().Endpoints(namespace).Get(context.TODO(), s.Name, metav1.GetOptions{})
-			describeService(&s, endpoints, w)
+	var services []ServiceDescription
+	if list, err := client.CoreV1().Services(namespace).List(context.TODO(), opts); err == nil {
+		for i := range list.Items {
+			endpoints, _ := client.CoreV1().Endpoints(namespace).Get(context.TODO(), list.Items[i].Name, metav1.GetOptions{})
+			services = append(services, describeService(&list.Items[i], endpoints))
 		}
 	}
+
+	return workloads, services
 }
 
-func showSecret(client kubernetes.Interface, namespace string, secretVolumes map[string]*core.SecretVolumeSource, w describe.PrefixWriter) {
+func describeSecrets(client kubernetes.Interface, namespace string, secretVolumes map[string]*core.SecretVolumeSource) []SecretDescription {
 	sc := client.CoreV1().Secrets(namespace)
 
-	for key, sv := range secretVolumes {
-		secret, err := sc.Get(context.TODO(), sv.SecretName, metav1.GetOptions{})
+	roles := make([]string, 0, len(secretVolumes))
+	for role := range secretVolumes {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	var secrets []SecretDescription
+	for _, role := range roles {
+		secret, err := sc.Get(context.TODO(), secretVolumes[role].SecretName, metav1.GetOptions{})
 		if err != nil {
 			continue
 		}
-		describeSecret(secret, key, w)
+		secrets = append(secrets, describeSecret(secret, role))
 	}
+	return secrets
 }
 
-func showTopology(client kubernetes.Interface, namespace string, selector labels.Selector, specific map[string]labels.Selector, w describe.PrefixWriter) {
-	w.Write(LEVEL_0, "\n")
-	w.Write(LEVEL_0, "Topology:\n")
-	w.Write(LEVEL_0, "  Type\tPod\tStartTime\tPhase\n")
-	w.Write(LEVEL_0, "  ----\t---\t---------\t-----\n")
+// describeTopology returns the pods selected by selector. The role of a pod is
+// the name of every specific selector it matches.
+func describeTopology(client kubernetes.Interface, namespace string, selector labels.Selector, specific map[string]labels.Selector) []MemberDescription {
+	members := make([]MemberDescription, 0)
 
-	pods, _ := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+	pods, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
 		LabelSelector: selector.String(),
 	})
+	if err != nil {
+		return members
+	}
+
+	keys := make([]string, 0, len(specific))
+	for key := range specific {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	for _, pod := range pods.Items {
-		types := make([]string, 0)
-		for key, val := range specific {
-			if val.Matches(labels.Set(pod.Labels)) {
-				types = append(types, key)
+	for i := range pods.Items {
+		roles := make([]string, 0)
+		for _, key := range keys {
+			if specific[key].Matches(labels.Set(pods.Items[i].Labels)) {
+				roles = append(roles, key)
 			}
 		}
-		w.Write(LEVEL_0, "  %s\t%s\t%s\t%s\n",
-			strings.Join(types, "|"),
-			pod.Name,
-			pod.Status.StartTime,
-			pod.Status.Phase,
-		)
+		members = append(members, describeMember(&pods.Items[i], strings.Join(roles, "|")))
+	}
+	return members
+}
+
+// describeMembers returns the pods selected by selector along with their role,
+// taken from the kubedb.com/role label. defaultRole is used for pods that do
+// not carry a role label.
+func describeMembers(client kubernetes.Interface, namespace string, selector labels.Selector, defaultRole string) []MemberDescription {
+	members := make([]MemberDescription, 0)
+
+	pods, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return members
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	for i := range pods.Items {
+		role, ok := pods.Items[i].Labels[api.LabelRole]
+		if !ok {
+			role = defaultRole
+		}
+		members = append(members, describeMember(&pods.Items[i], role))
+	}
+	return members
+}
+
+func describeMember(pod *core.Pod, role string) MemberDescription {
+	return MemberDescription{
+		Pod:       pod.Name,
+		Role:      role,
+		Ready:     isPodReady(pod),
+		StartTime: pod.Status.StartTime,
+		Phase:     pod.Status.Phase,
 	}
+}
 
-	w.Flush()
+func isPodReady(pod *core.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == core.PodReady {
+			return c.Status == core.ConditionTrue
+		}
+	}
+	return false
 }
 
 func getAccessModesAsString(modes []core.PersistentVolumeAccessMode) string {
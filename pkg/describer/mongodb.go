@@ -18,7 +18,8 @@ package describer
 
 import (
 	"context"
-	"io"
+	"fmt"
+	"sort"
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
@@ -26,29 +27,36 @@ import (
 
 	"github.com/appscode/go/types"
 	core "k8s.io/api/core/v1"
-	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
-	"kmodules.xyz/client-go/discovery"
 	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
-	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
 
 type MongoDBDescriber struct {
-	client kubernetes.Interface
-	kubedb cs.KubedbV1alpha1Interface
-	stash  stash.Interface
-	appcat appcat_cs.Interface
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
 }
 
 func (d *MongoDBDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
-	item, err := d.kubedb.MongoDBs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
 	if err != nil {
 		return "", err
 	}
+	return printDescription(desc)
+}
+
+func (d *MongoDBDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.MongoDBs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	selector := labels.SelectorFromSet(item.OffshootSelectors())
 
@@ -56,75 +64,130 @@ func (d *MongoDBDescriber) Describe(namespace, name string, describerSettings de
 	if describerSettings.ShowEvents {
 		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	return d.describeMongoDB(item, selector, events)
 }
 
-func (d *MongoDBDescriber) describeMongoDB(item *api.MongoDB, selector labels.Selector, events *core.EventList) (string, error) {
-	return tabbedString(func(out io.Writer) error {
-		w := describe.NewPrefixWriter(out)
-		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
-		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
-		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
-		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
-		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
-
-		if item.Spec.Replicas != nil {
-			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(item.Spec.Replicas))
-		}
-		w.Write(LEVEL_0, "Status:\t%s\n", string(item.Status.Phase))
-		if len(item.Status.Reason) > 0 {
-			w.Write(LEVEL_0, "Reason:\t%s\n", item.Status.Reason)
-		}
+func (d *MongoDBDescriber) describeMongoDB(item *api.MongoDB, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindMongoDB, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
 
-		describeStorage(item.Spec.StorageType, item.Spec.Storage, w)
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindMongoDB, string(item.Spec.Version))
 
-		w.Write(LEVEL_0, "Paused:\t%v\n", item.Spec.Paused)
-		w.Write(LEVEL_0, "Halted:\t%v\n", item.Spec.Halted)
-		w.Write(LEVEL_0, "Termination Policy:\t%v\n", item.Spec.TerminationPolicy)
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
 
-		showWorkload(d.client, item.Namespace, selector, w)
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
 
-		secretVolumes := make(map[string]*core.SecretVolumeSource)
-		if item.Spec.DatabaseSecret != nil {
-			secretVolumes["Database"] = item.Spec.DatabaseSecret
-		}
-		showSecret(d.client, item.Namespace, secretVolumes, w)
+	if item.Spec.ReplicaSet != nil {
+		rs := newSection("ReplicaSet")
+		rs.addField("Name", "%s", item.Spec.ReplicaSet.Name)
+		desc.addSection(rs)
+	}
 
-		if item.Spec.Monitor != nil {
-			describeMonitor(item.Spec.Monitor, w)
-		}
+	if item.Spec.ShardTopology != nil {
+		desc.addSection(describeShardTopology(item))
+		desc.Topology = describeShardMembers(d.client, item)
+	}
 
-		// Show initialization information
-		describeInitialization(item.Spec.Init, w)
+	desc.addSection(describeTLS(d.client, item.Namespace, item.Spec.TLS, certSecretNames(item.Name, api.MongoDBServerSecretSuffix, api.MongoDBExternalClientSecretSuffix, api.MongoDBExporterClientSecretSuffix)))
 
-		ab, err := d.appcat.AppcatalogV1alpha1().AppBindings(item.Namespace).Get(context.TODO(), item.Name, metav1.GetOptions{})
-		if err != nil && !kerr.IsNotFound(err) {
-			return err
-		}
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
 
-		// Show Backup information
-		if discovery.ExistsGroupKind(d.client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
-			err = showBackups(d.stash, ab, w)
-			if err != nil {
-				return err
-			}
-		}
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindMongoDB, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+// describeShardTopology describes the desired sharding layout from the spec.
+func describeShardTopology(item *api.MongoDB) *Section {
+	topology := item.Spec.ShardTopology
+
+	s := newSection("Sharding")
+	shard := s.addSection("Shard")
+	shard.addField("Shards", "%d", topology.Shard.Shards)
+	shard.addField("Replicas Per Shard", "%d", topology.Shard.Replicas)
+	if topology.Shard.Prefix != "" {
+		shard.addField("Prefix", "%s", topology.Shard.Prefix)
+	}
+	addVolumeClaim(shard, topology.Shard.Storage)
+
+	configServer := s.addSection("Config Server")
+	configServer.addField("Replicas", "%d", topology.ConfigServer.Replicas)
+	if topology.ConfigServer.Prefix != "" {
+		configServer.addField("Prefix", "%s", topology.ConfigServer.Prefix)
+	}
+	addVolumeClaim(configServer, topology.ConfigServer.Storage)
 
-		// Show AppBinding
-		if ab != nil {
-			err = showAppBinding(ab, w)
-			if err != nil {
-				return err
-			}
+	mongos := s.addSection("Mongos")
+	mongos.addField("Replicas", "%d", topology.Mongos.Replicas)
+	if topology.Mongos.Prefix != "" {
+		mongos.addField("Prefix", "%s", topology.Mongos.Prefix)
+	}
+	return s
+}
+
+// describeShardMembers returns the running pods of a sharded MongoDB. The role
+// of a pod is the component it belongs to, i.e. its shard, configsvr or mongos.
+func describeShardMembers(client kubernetes.Interface, item *api.MongoDB) []MemberDescription {
+	type component struct {
+		name     string
+		selector labels.Selector
+	}
+	var components []component
+	for i := int32(0); i < item.Spec.ShardTopology.Shard.Shards; i++ {
+		components = append(components, component{
+			name:     fmt.Sprintf("shard%d", i),
+			selector: labels.SelectorFromSet(item.ShardSelectors(i)),
+		})
+	}
+	components = append(components,
+		component{name: "configsvr", selector: labels.SelectorFromSet(item.ConfigSvrSelectors())},
+		component{name: "mongos", selector: labels.SelectorFromSet(item.MongosSelectors())},
+	)
+
+	members := make([]MemberDescription, 0)
+	for _, c := range components {
+		pods, err := client.CoreV1().Pods(item.Namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: c.selector.String(),
+		})
+		if err != nil {
+			continue
 		}
-		if events != nil {
-			DescribeEvents(events, w)
+		sort.Slice(pods.Items, func(i, j int) bool {
+			return pods.Items[i].Name < pods.Items[j].Name
+		})
+		for i := range pods.Items {
+			members = append(members, describeMember(&pods.Items[i], c.name))
 		}
-
-		return nil
-	})
+	}
+	return members
 }
@@ -18,7 +18,6 @@ package describer
 
 import (
 	"context"
-	"io"
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
@@ -26,29 +25,36 @@ import (
 
 	"github.com/appscode/go/types"
 	core "k8s.io/api/core/v1"
-	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
-	"kmodules.xyz/client-go/discovery"
 	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
-	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
 
 type MySQLDescriber struct {
-	client kubernetes.Interface
-	kubedb cs.KubedbV1alpha1Interface
-	stash  stash.Interface
-	appcat appcat_cs.Interface
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
 }
 
 func (d *MySQLDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
-	item, err := d.kubedb.MySQLs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
 	if err != nil {
 		return "", err
 	}
+	return printDescription(desc)
+}
+
+func (d *MySQLDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.MySQLs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	selector := labels.SelectorFromSet(item.OffshootSelectors())
 
@@ -56,76 +62,78 @@ func (d *MySQLDescriber) Describe(namespace, name string, describerSettings desc
 	if describerSettings.ShowEvents {
 		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	return d.describeMySQL(item, selector, events)
 }
 
-func (d *MySQLDescriber) describeMySQL(item *api.MySQL, selector labels.Selector, events *core.EventList) (string, error) {
-	return tabbedString(func(out io.Writer) error {
-		w := describe.NewPrefixWriter(out)
-		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
-		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
-		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
-		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
-		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
-
-		if item.Spec.Replicas != nil {
-			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(item.Spec.Replicas))
-		}
-		w.Write(LEVEL_0, "Status:\t%s\n", string(item.Status.Phase))
-		if len(item.Status.Reason) > 0 {
-			w.Write(LEVEL_0, "Reason:\t%s\n", item.Status.Reason)
-		}
+func (d *MySQLDescriber) describeMySQL(item *api.MySQL, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindMySQL, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
 
-		describeStorage(item.Spec.StorageType, item.Spec.Storage, w)
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindMySQL, string(item.Spec.Version))
 
-		w.Write(LEVEL_0, "Paused:\t%v\n", item.Spec.Paused)
-		w.Write(LEVEL_0, "Halted:\t%v\n", item.Spec.Halted)
-		w.Write(LEVEL_0, "Termination Policy:\t%v\n", item.Spec.TerminationPolicy)
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
 
-		showWorkload(d.client, item.Namespace, selector, w)
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
 
-		secretVolumes := make(map[string]*core.SecretVolumeSource)
-		if item.Spec.DatabaseSecret != nil {
-			secretVolumes["Database"] = item.Spec.DatabaseSecret
-		}
-		showSecret(d.client, item.Namespace, secretVolumes, w)
+	if item.Spec.Topology != nil {
+		desc.addSection(describeMySQLTopology(item.Spec.Topology))
+		desc.Topology = describeMembers(d.client, item.Namespace, selector, ValueNone)
+	}
 
-		if item.Spec.Monitor != nil {
-			describeMonitor(item.Spec.Monitor, w)
-		}
+	desc.addSection(describeTLS(d.client, item.Namespace, item.Spec.TLS, certSecretNames(item.Name, "-"+api.MySQLServerCertSuffix, "-"+api.MySQLClientCertSuffix, "-"+api.MySQLExporterClientCertSuffix)))
 
-		// Show initialization information
-		describeInitialization(item.Spec.Init, w)
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
 
-		ab, err := d.appcat.AppcatalogV1alpha1().AppBindings(item.Namespace).Get(context.TODO(), item.Name, metav1.GetOptions{})
-		if err != nil && !kerr.IsNotFound(err) {
-			return err
-		}
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
 
-		// Show Backup information
-		if discovery.ExistsGroupKind(d.client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
-			err = showBackups(d.stash, ab, w)
-			if err != nil {
-				return err
-			}
-		}
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindMySQL, item.ObjectMeta)
 
-		// Show AppBinding
-		if ab != nil {
-			err = showAppBinding(ab, w)
-			if err != nil {
-				return err
-			}
-		}
+	desc.Events = describeEvents(events)
 
-		if events != nil {
-			DescribeEvents(events, w)
-		}
+	return desc, nil
+}
 
-		return nil
-	})
+func describeMySQLTopology(topology *api.MySQLClusterTopology) *Section {
+	s := newSection("Cluster Topology")
+	if topology.Mode != nil {
+		s.addField("Mode", "%s", *topology.Mode)
+	}
+	if topology.Group == nil {
+		return s
+	}
+	g := s.addSection("Group")
+	if topology.Group.Mode != nil {
+		g.addField("Mode", "%s", *topology.Group.Mode)
+	}
+	g.addField("Name", "%s", valueOrNone(topology.Group.Name))
+	if topology.Group.BaseServerID != nil {
+		g.addField("Base Server ID", "%d", *topology.Group.BaseServerID)
+	}
+	return s
 }
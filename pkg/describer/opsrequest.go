@@ -0,0 +1,468 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	opsapi "kubedb.dev/apimachinery/apis/ops/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+
+	"github.com/appscode/go/types"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	kmapi "kmodules.xyz/client-go/api/v1"
+	"kmodules.xyz/client-go/discovery"
+)
+
+type opsRequestKind struct {
+	resource     string
+	databaseKind string
+}
+
+// opsRequestKinds maps every OpsRequest kind to its resource and to the kind
+// of the database it operates on.
+var opsRequestKinds = map[string]opsRequestKind{
+	opsapi.ResourceKindElasticsearchOpsRequest: {opsapi.ResourcePluralElasticsearchOpsRequest, api.ResourceKindElasticsearch},
+	opsapi.ResourceKindEtcdOpsRequest:          {opsapi.ResourcePluralEtcdOpsRequest, api.ResourceKindEtcd},
+	opsapi.ResourceKindMemcachedOpsRequest:     {opsapi.ResourcePluralMemcachedOpsRequest, api.ResourceKindMemcached},
+	opsapi.ResourceKindMongoDBOpsRequest:       {opsapi.ResourcePluralMongoDBOpsRequest, api.ResourceKindMongoDB},
+	opsapi.ResourceKindMySQLOpsRequest:         {opsapi.ResourcePluralMySQLOpsRequest, api.ResourceKindMySQL},
+	opsapi.ResourceKindPerconaXtraDBOpsRequest: {opsapi.ResourcePluralPerconaXtraDBOpsRequest, api.ResourceKindPerconaXtraDB},
+	opsapi.ResourceKindPgBouncerOpsRequest:     {opsapi.ResourcePluralPgBouncerOpsRequest, api.ResourceKindPgBouncer},
+	opsapi.ResourceKindPostgresOpsRequest:      {opsapi.ResourcePluralPostgresOpsRequest, api.ResourceKindPostgres},
+	opsapi.ResourceKindProxySQLOpsRequest:      {opsapi.ResourcePluralProxySQLOpsRequest, api.ResourceKindProxySQL},
+	opsapi.ResourceKindRedisOpsRequest:         {opsapi.ResourcePluralRedisOpsRequest, api.ResourceKindRedis},
+}
+
+// OpsRequestKinds returns every OpsRequest kind in alphabetical order.
+func OpsRequestKinds() []string {
+	kinds := make([]string, 0, len(opsRequestKinds))
+	for k := range opsRequestKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// OpsRequestResource returns the resource of an OpsRequest kind.
+func OpsRequestResource(kind string) schema.GroupVersionResource {
+	return opsapi.SchemeGroupVersion.WithResource(opsRequestKinds[kind].resource)
+}
+
+// OpsRequestKindFor returns the OpsRequest kind of a database kind and its
+// resource.
+func OpsRequestKindFor(databaseKind string) (string, schema.GroupVersionResource, bool) {
+	for k, v := range opsRequestKinds {
+		if v.databaseKind == databaseKind {
+			return k, OpsRequestResource(k), true
+		}
+	}
+	return "", schema.GroupVersionResource{}, false
+}
+
+// OpsRequestDescriber describes any of the OpsRequest kinds. There is no typed
+// client for the ops group, so objects are read with the dynamic client and
+// converted to their typed form.
+type OpsRequestDescriber struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+	kind    string
+}
+
+func (d *OpsRequestDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	u, err := d.dynamic.Resource(OpsRequestResource(d.kind)).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	item, err := NewOpsRequest(u)
+	if err != nil {
+		return "", err
+	}
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, u)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return d.describeOpsRequest(item, events)
+}
+
+func (d *OpsRequestDescriber) describeOpsRequest(item *OpsRequest, events *core.EventList) (string, error) {
+	return tabbedString(func(out io.Writer) error {
+		w := describe.NewPrefixWriter(out)
+		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
+		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
+		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
+		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
+		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
+		w.Write(LEVEL_0, "Database:\t%s/%s\n", opsRequestKinds[item.Kind].databaseKind, valueOrNone(item.DatabaseRef.Name))
+		w.Write(LEVEL_0, "Type:\t%s\n", item.Type)
+		w.Write(LEVEL_0, "Phase:\t%s\n", valueOrNone(string(item.Phase)))
+		w.Write(LEVEL_0, "Duration:\t%s\n", item.elapsed(time.Now()))
+
+		w.Write(LEVEL_0, "\n")
+		printSection(LEVEL_0, item.Parameters, w)
+
+		w.Write(LEVEL_0, "\n")
+		printSection(LEVEL_0, describeApproval(item), w)
+
+		w.Write(LEVEL_0, "\n")
+		printSection(LEVEL_0, describeTimeline(item, time.Now()), w)
+
+		if events != nil {
+			printEvents(describeEvents(events), w)
+		}
+
+		return nil
+	})
+}
+
+// OpsRequest holds the fields that every OpsRequest kind has in common. The
+// operation specific parameters are kept as a Section, since their shape
+// differs from one database to another.
+type OpsRequest struct {
+	metav1.ObjectMeta
+
+	Kind        string
+	DatabaseRef core.LocalObjectReference
+	Type        opsapi.OpsRequestType
+	Parameters  *Section
+	Phase       opsapi.OpsRequestPhase
+	Conditions  []kmapi.Condition
+}
+
+// NewOpsRequest reads the common fields of an OpsRequest of any kind.
+func NewOpsRequest(u *unstructured.Unstructured) (*OpsRequest, error) {
+	o := &OpsRequest{Kind: u.GetKind()}
+	switch o.Kind {
+	case opsapi.ResourceKindElasticsearchOpsRequest:
+		var obj opsapi.ElasticsearchOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+		if obj.Spec.HorizontalScaling != nil {
+			h := o.Parameters.addSection("Horizontal Scaling")
+			addReplicas(h, "Master", obj.Spec.HorizontalScaling.Master)
+			addReplicas(h, "Data", obj.Spec.HorizontalScaling.Data)
+			addReplicas(h, "Client", obj.Spec.HorizontalScaling.Client)
+		}
+	case opsapi.ResourceKindEtcdOpsRequest:
+		var obj opsapi.EtcdOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindMemcachedOpsRequest:
+		var obj opsapi.MemcachedOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindMongoDBOpsRequest:
+		var obj opsapi.MongoDBOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+		if hs := obj.Spec.HorizontalScaling; hs != nil {
+			h := o.Parameters.addSection("Horizontal Scaling")
+			addReplicas(h, "Replicas", hs.Replicas)
+			if hs.Shard != nil {
+				h.addField("Shards", "%d", hs.Shard.Shards)
+				h.addField("Replicas per Shard", "%d", hs.Shard.Replicas)
+			}
+			if hs.ConfigServer != nil {
+				h.addField("ConfigServer Replicas", "%d", hs.ConfigServer.Replicas)
+			}
+			if hs.Mongos != nil {
+				h.addField("Mongos Replicas", "%d", hs.Mongos.Replicas)
+			}
+		}
+		if vs := obj.Spec.VerticalScaling; vs != nil {
+			v := o.Parameters.addSection("Vertical Scaling")
+			addTargetResources(v, "Standalone", vs.Standalone)
+			addTargetResources(v, "Shard", vs.Shard)
+			addTargetResources(v, "ConfigServer", vs.ConfigServer)
+			addTargetResources(v, "Mongos", vs.Mongos)
+			addTargetResources(v, "Exporter", vs.Exporter)
+		}
+	case opsapi.ResourceKindMySQLOpsRequest:
+		var obj opsapi.MySQLOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+		if obj.Spec.StatefulSetOrdinal != nil {
+			o.Parameters.addField("StatefulSet Ordinal", "%d", *obj.Spec.StatefulSetOrdinal)
+		}
+		if hs := obj.Spec.HorizontalScaling; hs != nil {
+			h := o.Parameters.addSection("Horizontal Scaling")
+			addReplicas(h, "Member", hs.Member)
+			if hs.MemberWeight != 0 {
+				h.addField("Member Weight", "%d", hs.MemberWeight)
+			}
+		}
+		if vs := obj.Spec.VerticalScaling; vs != nil {
+			v := o.Parameters.addSection("Vertical Scaling")
+			addTargetResources(v, "MySQL", vs.MySQL)
+			addTargetResources(v, "Exporter", vs.Exporter)
+		}
+	case opsapi.ResourceKindPerconaXtraDBOpsRequest:
+		var obj opsapi.PerconaXtraDBOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindPgBouncerOpsRequest:
+		var obj opsapi.PgBouncerOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindPostgresOpsRequest:
+		var obj opsapi.PostgresOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindProxySQLOpsRequest:
+		var obj opsapi.ProxySQLOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	case opsapi.ResourceKindRedisOpsRequest:
+		var obj opsapi.RedisOpsRequest
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &obj); err != nil {
+			return nil, err
+		}
+		o.ObjectMeta, o.DatabaseRef, o.Type = obj.ObjectMeta, obj.Spec.DatabaseRef, obj.Spec.Type
+		o.Phase, o.Conditions = obj.Status.Phase, obj.Status.Conditions
+		o.Parameters = describeOpsParameters(obj.Spec.Upgrade)
+	default:
+		return nil, fmt.Errorf("unknown OpsRequest kind %q", o.Kind)
+	}
+	return o, nil
+}
+
+// IsFinished reports whether the operation has reached a terminal phase.
+func (o *OpsRequest) IsFinished() bool {
+	switch o.Phase {
+	case opsapi.OpsRequestPhaseSuccessful, opsapi.OpsRequestPhaseFailed, opsapi.OpsRequestDenied:
+		return true
+	}
+	return false
+}
+
+// elapsed returns how long the operation has been running. For a finished
+// operation it is measured up to its last condition.
+func (o *OpsRequest) elapsed(now time.Time) string {
+	if o.CreationTimestamp.IsZero() {
+		return "<unknown>"
+	}
+	end := now
+	if o.IsFinished() && len(o.Conditions) > 0 {
+		end = o.CreationTimestamp.Time
+		for _, c := range o.Conditions {
+			if c.LastTransitionTime.After(end) {
+				end = c.LastTransitionTime.Time
+			}
+		}
+	}
+	return duration.HumanDuration(end.Sub(o.CreationTimestamp.Time))
+}
+
+func describeOpsParameters(upgrade *opsapi.UpgradeSpec) *Section {
+	s := newSection("Parameters")
+	if upgrade != nil {
+		s.addField("Target Version", "%s", valueOrNone(upgrade.TargetVersion))
+	}
+	return s
+}
+
+func addReplicas(s *Section, name string, replicas *int32) {
+	if replicas != nil {
+		s.addField(name, "%d", types.Int32(replicas))
+	}
+}
+
+func addTargetResources(s *Section, name string, resources *core.ResourceRequirements) {
+	if resources != nil {
+		addResources(s.addSection(name), *resources)
+	}
+}
+
+// describeApproval shows whether the operation was approved or denied. The
+// latest of the Approved and Denied conditions wins.
+func describeApproval(o *OpsRequest) *Section {
+	s := newSection("Approval")
+	var last *kmapi.Condition
+	for i := range o.Conditions {
+		c := &o.Conditions[i]
+		if c.Type != opsapi.AccessApproved && c.Type != opsapi.AccessDenied {
+			continue
+		}
+		if last == nil || !c.LastTransitionTime.Before(&last.LastTransitionTime) {
+			last = c
+		}
+	}
+	if last == nil {
+		if o.Phase == opsapi.OpsRequestPhaseWaitingForApproval {
+			s.addField("State", "WaitingForApproval")
+		}
+		return s
+	}
+	s.addField("State", "%s", last.Type)
+	s.addField("Time", "%s", timeToString(&last.LastTransitionTime))
+	s.addField("Reason", "%s", valueOrNone(last.Reason))
+	s.addField("Message", "%s", valueOrNone(last.Message))
+	return s
+}
+
+// describeTimeline lists the conditions in the order they happened. A step
+// lasts until the next condition; the last step of an operation that is still
+// running lasts until now.
+func describeTimeline(o *OpsRequest, now time.Time) *Section {
+	s := newSection("Timeline")
+	if len(o.Conditions) == 0 {
+		return s
+	}
+
+	conditions := make([]kmapi.Condition, len(o.Conditions))
+	copy(conditions, o.Conditions)
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return conditions[i].LastTransitionTime.Before(&conditions[j].LastTransitionTime)
+	})
+
+	t := s.setTable("Time", "Type", "Status", "Reason", "Took", "Message")
+	for i, c := range conditions {
+		took := "-"
+		if i+1 < len(conditions) {
+			took = duration.HumanDuration(conditions[i+1].LastTransitionTime.Sub(c.LastTransitionTime.Time))
+		} else if !o.IsFinished() {
+			took = duration.HumanDuration(now.Sub(c.LastTransitionTime.Time)) + " (running)"
+		}
+		t.addRow(timeToString(&c.LastTransitionTime), c.Type, c.Status, valueOrNone(c.Reason), took, valueOrNone(c.Message))
+	}
+	return s
+}
+
+// describeOpsRequests returns the OpsRequests whose DatabaseRef points at the
+// database, newest first. It returns nil if the database kind has no
+// OpsRequest kind or the ops.kubedb.com group is not installed. OpsRequests
+// are an addition to the description, so a failed List is returned as a
+// message to show in their section, and objects that can't be read are left
+// out.
+func describeOpsRequests(client kubernetes.Interface, dc dynamic.Interface, databaseKind string, meta metav1.ObjectMeta) ([]OpsRequestDescription, string) {
+	kind, gvr, ok := OpsRequestKindFor(databaseKind)
+	if !ok || !discovery.ExistsGroupKind(client.Discovery(), opsapi.SchemeGroupVersion.Group, kind) {
+		return nil, ""
+	}
+
+	list, err := dc.Resource(gvr).Namespace(meta.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	out := make([]OpsRequestDescription, 0)
+	for i := range list.Items {
+		o, err := NewOpsRequest(&list.Items[i])
+		if err != nil {
+			continue
+		}
+		if o.DatabaseRef.Name != meta.Name {
+			continue
+		}
+		out = append(out, OpsRequestDescription{
+			Name:              o.Name,
+			Type:              o.Type,
+			Phase:             o.Phase,
+			CreationTimestamp: o.CreationTimestamp,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[j].CreationTimestamp.Before(&out[i].CreationTimestamp)
+	})
+	return out, ""
+}
+
+// isActive reports whether the operation is still in flight, i.e. it is
+// either running or waiting to be approved.
+func (o OpsRequestDescription) isActive() bool {
+	return o.Phase == opsapi.OpsRequestPhaseProgressing || o.Phase == opsapi.OpsRequestPhaseWaitingForApproval
+}
+
+func printOpsRequests(ops []OpsRequestDescription, listErr string, w describe.PrefixWriter) {
+	w.Write(LEVEL_0, "\n")
+	w.Write(LEVEL_0, "Ops Requests:\n")
+	if listErr != "" {
+		w.Write(LEVEL_1, "WARNING:\tcan not list OpsRequests: %s\n", listErr)
+		return
+	}
+	if len(ops) == 0 {
+		w.Write(LEVEL_1, "No operation has been requested.\n")
+		return
+	}
+
+	// Operations in flight are listed first so that they are not lost among the finished ones.
+	for _, o := range ops {
+		if o.isActive() {
+			w.Write(LEVEL_1, "IN PROGRESS:\t%s (%s, %s)\n", o.Name, o.Type, o.Phase)
+		}
+	}
+
+	w.Write(LEVEL_1, "Name\tType\tPhase\tAge\n")
+	w.Write(LEVEL_1, "----\t----\t-----\t---\n")
+	for _, o := range ops {
+		w.Write(LEVEL_1, "%s\t%s\t%s\t%s\n", o.Name, o.Type, valueOrNone(string(o.Phase)), translateTimestamp(o.CreationTimestamp))
+	}
+}
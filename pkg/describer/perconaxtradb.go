@@ -0,0 +1,130 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+	cs "kubedb.dev/apimachinery/client/clientset/versioned/typed/kubedb/v1alpha1"
+
+	"github.com/appscode/go/types"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
+)
+
+type PerconaXtraDBDescriber struct {
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
+}
+
+func (d *PerconaXtraDBDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
+	if err != nil {
+		return "", err
+	}
+	return printDescription(desc)
+}
+
+func (d *PerconaXtraDBDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.PerconaXtraDBs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(item.OffshootSelectors())
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d.describePerconaXtraDB(item, selector, events)
+}
+
+func (d *PerconaXtraDBDescriber) describePerconaXtraDB(item *api.PerconaXtraDB, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindPerconaXtraDB, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
+
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindPerconaXtraDB, string(item.Spec.Version))
+
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
+
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
+
+	if item.IsCluster() {
+		desc.addSection(describeGaleraCluster(item))
+		// Galera is multi-master, so pods without a role label are shown as plain members.
+		desc.Topology = describeMembers(d.client, item.Namespace, selector, "member")
+	}
+
+	desc.addSection(describeIssuedTLS(d.client, item.Namespace, item.Name, item.Spec.TLS))
+
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
+
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindPerconaXtraDB, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+// describeGaleraCluster describes the Galera cluster of a clustered PerconaXtraDB.
+func describeGaleraCluster(item *api.PerconaXtraDB) *Section {
+	s := newSection("Galera Cluster")
+	s.addField("Cluster Name", "%s", item.ClusterName())
+	s.addField("Cluster Size", "%d", types.Int32(item.Spec.Replicas))
+	s.addField("Governing Service", "%s", item.GoverningServiceName())
+	return s
+}
@@ -0,0 +1,206 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+	"sort"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+	cs "kubedb.dev/apimachinery/client/clientset/versioned/typed/kubedb/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
+)
+
+// Settings PgBouncer falls back to when they are not set in the ConnectionPool spec.
+const (
+	pgBouncerDefaultPort                 = 5432
+	pgBouncerDefaultPoolMode             = "session"
+	pgBouncerDefaultMaxClientConnections = 100
+	pgBouncerDefaultPoolSize             = 20
+)
+
+type PgBouncerDescriber struct {
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
+}
+
+func (d *PgBouncerDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
+	if err != nil {
+		return "", err
+	}
+	return printDescription(desc)
+}
+
+func (d *PgBouncerDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.PgBouncers(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(item.OffshootSelectors())
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d.describePgBouncer(item, selector, events)
+}
+
+func (d *PgBouncerDescriber) describePgBouncer(item *api.PgBouncer, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindPgBouncer, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:  item.Spec.Version,
+		Replicas: item.Spec.Replicas,
+		Paused:   item.Spec.Paused,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
+
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindPgBouncer, item.Spec.Version)
+
+	desc.addSection(describePooledDatabases(item.Spec.Databases))
+
+	desc.addSection(describeConnectionPool(item.Spec.ConnectionPool))
+
+	if item.Spec.UserListSecretRef != nil {
+		desc.addSection(describeUserListSecret(d.client, item.Namespace, item.Spec.UserListSecretRef.Name))
+	}
+
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
+
+	desc.addSection(describeTLS(d.client, item.Namespace, item.Spec.TLS, certSecretNames(item.Name, api.PgBouncerServingServerSuffix, api.PgBouncerServingClientSuffix, api.PgBouncerExporterClientCertSuffix)))
+
+	desc.Monitor = item.Spec.Monitor
+
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindPgBouncer, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+func describePooledDatabases(databases []api.Databases) *Section {
+	s := newSection("Databases")
+	if len(databases) == 0 {
+		return s
+	}
+	t := s.setTable("Alias", "AppBinding", "Database", "Secret")
+	for _, db := range databases {
+		secret := ValueNone
+		if db.DatabaseSecretRef != nil {
+			secret = db.DatabaseSecretRef.Name
+		}
+		t.addRow(db.Alias, db.DatabaseRef.Namespace+"/"+db.DatabaseRef.Name, db.DatabaseName, secret)
+	}
+	return s
+}
+
+// describeConnectionPool describes the effective pool settings. Values missing
+// from the spec are shown with the default PgBouncer applies for them.
+func describeConnectionPool(pool *api.ConnectionPoolConfig) *Section {
+	if pool == nil {
+		pool = &api.ConnectionPoolConfig{}
+	}
+
+	s := newSection("Connection Pool")
+	if pool.Port != nil {
+		s.addField("Port", "%d", *pool.Port)
+	} else {
+		s.addField("Port", "%d (default)", pgBouncerDefaultPort)
+	}
+	if pool.PoolMode != "" {
+		s.addField("Pool Mode", "%s", pool.PoolMode)
+	} else {
+		s.addField("Pool Mode", "%s (default)", pgBouncerDefaultPoolMode)
+	}
+	if pool.MaxClientConnections != nil {
+		s.addField("Max Client Connections", "%d", *pool.MaxClientConnections)
+	} else {
+		s.addField("Max Client Connections", "%d (default)", pgBouncerDefaultMaxClientConnections)
+	}
+	if pool.DefaultPoolSize != nil {
+		s.addField("Default Pool Size", "%d", *pool.DefaultPoolSize)
+	} else {
+		s.addField("Default Pool Size", "%d (default)", pgBouncerDefaultPoolSize)
+	}
+	if pool.MinPoolSize != nil {
+		s.addField("Min Pool Size", "%d", *pool.MinPoolSize)
+	}
+	if pool.ReservePoolSize != nil {
+		s.addField("Reserve Pool Size", "%d", *pool.ReservePoolSize)
+	}
+	if pool.ReservePoolTimeoutSeconds != nil {
+		s.addField("Reserve Pool Timeout", "%ds", *pool.ReservePoolTimeoutSeconds)
+	}
+	if pool.MaxDBConnections != nil {
+		s.addField("Max DB Connections", "%d", *pool.MaxDBConnections)
+	}
+	if pool.MaxUserConnections != nil {
+		s.addField("Max User Connections", "%d", *pool.MaxUserConnections)
+	}
+	if pool.AuthType != "" {
+		s.addField("Auth Type", "%s", pool.AuthType)
+	}
+	if pool.AuthUser != "" {
+		s.addField("Auth User", "%s", pool.AuthUser)
+	}
+	if len(pool.AdminUsers) > 0 {
+		s.addField("Admin Users", "%v", pool.AdminUsers)
+	}
+	return s
+}
+
+// describeUserListSecret describes the keys of the userlist secret. Values are never shown.
+func describeUserListSecret(client kubernetes.Interface, namespace, name string) *Section {
+	s := newSection("UserList Secret")
+	s.addField("Name", "%s", name)
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		s.addField("Error", "%v", err)
+		return s
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s.addField("Keys", "%s", joinOrNone(keys))
+	return s
+}
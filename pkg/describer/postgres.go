@@ -18,7 +18,7 @@ package describer
 
 import (
 	"context"
-	"io"
+	"strconv"
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
@@ -29,26 +29,34 @@ import (
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
-	"kmodules.xyz/client-go/discovery"
 	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
-	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
 
 type PostgresDescriber struct {
-	client kubernetes.Interface
-	kubedb cs.KubedbV1alpha1Interface
-	stash  stash.Interface
-	appcat appcat_cs.Interface
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
 }
 
 func (d *PostgresDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
-	item, err := d.kubedb.Postgreses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
 	if err != nil {
 		return "", err
 	}
+	return printDescription(desc)
+}
+
+func (d *PostgresDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.Postgreses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	selector := labels.SelectorFromSet(item.OffshootSelectors())
 
@@ -56,84 +64,127 @@ func (d *PostgresDescriber) Describe(namespace, name string, describerSettings d
 	if describerSettings.ShowEvents {
 		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	return d.describePostgres(item, selector, events)
 }
 
-func (d *PostgresDescriber) describePostgres(item *api.Postgres, selector labels.Selector, events *core.EventList) (string, error) {
-	return tabbedString(func(out io.Writer) error {
-		w := describe.NewPrefixWriter(out)
-		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
-		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
-		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
-		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
-		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
-
-		if item.Spec.Replicas != nil {
-			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(item.Spec.Replicas))
-		}
-		w.Write(LEVEL_0, "Status:\t%s\n", string(item.Status.Phase))
-		if len(item.Status.Reason) > 0 {
-			w.Write(LEVEL_0, "Reason:\t%s\n", item.Status.Reason)
-		}
+func (d *PostgresDescriber) describePostgres(item *api.Postgres, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindPostgres, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
 
-		describeArchiver(item.Spec.Archiver, w)
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindPostgres, string(item.Spec.Version))
 
-		describeStorage(item.Spec.StorageType, item.Spec.Storage, w)
+	desc.addSection(describeArchiver(item.Spec.Archiver))
 
-		w.Write(LEVEL_0, "Paused:\t%v\n", item.Spec.Paused)
-		w.Write(LEVEL_0, "Halted:\t%v\n", item.Spec.Halted)
-		w.Write(LEVEL_0, "Termination Policy:\t%v\n", item.Spec.TerminationPolicy)
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
 
-		showWorkload(d.client, item.Namespace, selector, w)
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.DatabaseSecret != nil {
+		secretVolumes["Database"] = item.Spec.DatabaseSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
 
-		secretVolumes := make(map[string]*core.SecretVolumeSource)
-		if item.Spec.DatabaseSecret != nil {
-			secretVolumes["Database"] = item.Spec.DatabaseSecret
-		}
-		showSecret(d.client, item.Namespace, secretVolumes, w)
+	specific := map[string]labels.Selector{
+		"primary": labels.SelectorFromSet(map[string]string{api.LabelRole: "primary"}),
+		"replica": labels.SelectorFromSet(map[string]string{api.LabelRole: "replica"}),
+	}
+	desc.Topology = describeTopology(d.client, item.Namespace, selector, specific)
 
-		specific := map[string]labels.Selector{
-			"primary": labels.SelectorFromSet(map[string]string{"kubedb.com/role": "primary"}),
-			"replica": labels.SelectorFromSet(map[string]string{"kubedb.com/role": "replica"}),
-		}
-		showTopology(d.client, item.Namespace, selector, specific, w)
+	desc.addSection(describePostgresHA(d.client, item))
 
-		if item.Spec.Monitor != nil {
-			describeMonitor(item.Spec.Monitor, w)
-		}
+	desc.addSection(describeIssuedTLS(d.client, item.Namespace, item.Name, item.Spec.TLS))
 
-		// Show initialization information
-		describeInitialization(item.Spec.Init, w)
+	desc.Monitor = item.Spec.Monitor
+	desc.Init = item.Spec.Init
 
-		ab, err := d.appcat.AppcatalogV1alpha1().AppBindings(item.Namespace).Get(context.TODO(), item.Name, metav1.GetOptions{})
-		if err != nil && !kerr.IsNotFound(err) {
-			return err
-		}
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
 
-		// Show Backup information
-		if discovery.ExistsGroupKind(d.client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
-			err = showBackups(d.stash, ab, w)
-			if err != nil {
-				return err
-			}
-		}
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindPostgres, item.ObjectMeta)
 
-		// Show AppBinding
-		if ab != nil {
-			err = showAppBinding(ab, w)
-			if err != nil {
-				return err
-			}
-		}
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+// describePostgresHA describes the standby, streaming and leader election
+// settings of a Postgres along with the service that exposes its replicas.
+func describePostgresHA(client kubernetes.Interface, item *api.Postgres) *Section {
+	s := newSection("High Availability")
+	if item.Spec.StandbyMode != nil {
+		s.addField("Standby Mode", "%s", *item.Spec.StandbyMode)
+	}
+	if item.Spec.StreamingMode != nil {
+		s.addField("Streaming Mode", "%s", *item.Spec.StreamingMode)
+	}
+	if le := item.Spec.LeaderElection; le != nil {
+		l := s.addSection("Leader Election")
+		l.addField("Lease Duration", "%ds", le.LeaseDurationSeconds)
+		l.addField("Renew Deadline", "%ds", le.RenewDeadlineSeconds)
+		l.addField("Retry Period", "%ds", le.RetryPeriodSeconds)
+	}
 
-		if events != nil {
-			DescribeEvents(events, w)
+	tpl := item.Spec.ReplicaServiceTemplate
+	if tpl.Spec.Type != "" || len(tpl.Spec.Ports) > 0 || len(tpl.Annotations) > 0 {
+		t := s.addSection("Replica Service Template")
+		if len(tpl.Annotations) > 0 {
+			t.addField("Annotations", "%s", labels.Set(tpl.Annotations).String())
+		}
+		if tpl.Spec.Type != "" {
+			t.addField("Type", "%s", tpl.Spec.Type)
+		}
+		if len(tpl.Spec.Ports) > 0 {
+			ports := t.addSection("Ports")
+			for _, p := range tpl.Spec.Ports {
+				name := p.Name
+				if name == "" {
+					name = strconv.Itoa(int(p.Port))
+				}
+				if p.NodePort != 0 {
+					ports.addField(name, "%d (NodePort %d)", p.Port, p.NodePort)
+				} else {
+					ports.addField(name, "%d", p.Port)
+				}
+			}
 		}
+	}
 
-		return nil
-	})
+	rs := s.addSection("Replica Service")
+	name := item.ReplicasServiceName()
+	svc, err := client.CoreV1().Services(item.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if kerr.IsNotFound(err) {
+		rs.addField("Name", "%s (not found)", name)
+		return s
+	} else if err != nil {
+		rs.addField("Name", "%s (error: %v)", name, err)
+		return s
+	}
+	rs.addField("Name", "%s", svc.Name)
+	rs.addField("Type", "%s", svc.Spec.Type)
+	rs.addField("IP", "%s", svc.Spec.ClusterIP)
+	endpoints, err := client.CoreV1().Endpoints(item.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+	if err != nil {
+		endpoints = &core.Endpoints{}
+	}
+	rs.addField("Endpoints", "%s", formatEndpoints(endpoints, nil))
+	return s
 }
@@ -90,3 +90,10 @@ func timeToString(t *metav1.Time) string {
 
 	return t.Format(time.RFC1123Z)
 }
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return ValueNone
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
+	cs "kubedb.dev/apimachinery/client/clientset/versioned/typed/kubedb/v1alpha1"
+
+	"github.com/appscode/go/types"
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
+	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
+)
+
+type ProxySQLDescriber struct {
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
+}
+
+func (d *ProxySQLDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
+	if err != nil {
+		return "", err
+	}
+	return printDescription(desc)
+}
+
+func (d *ProxySQLDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.ProxySQLs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(item.OffshootSelectors())
+
+	var events *core.EventList
+	if describerSettings.ShowEvents {
+		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d.describeProxySQL(item, selector, events)
+}
+
+func (d *ProxySQLDescriber) describeProxySQL(item *api.ProxySQL, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindProxySQL, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:  item.Spec.Version,
+		Replicas: item.Spec.Replicas,
+		Paused:   item.Spec.Paused,
+	}
+	if item.Spec.Mode != nil {
+		desc.Spec.Mode = string(*item.Spec.Mode)
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
+
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindProxySQL, item.Spec.Version)
+
+	backend, err := d.describeBackend(item)
+	if err != nil {
+		return nil, err
+	}
+	desc.addSection(backend)
+
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
+
+	secretVolumes := make(map[string]*core.SecretVolumeSource)
+	if item.Spec.ProxySQLSecret != nil {
+		secretVolumes["ProxySQL"] = item.Spec.ProxySQLSecret
+	}
+	desc.Secrets = describeSecrets(d.client, item.Namespace, secretVolumes)
+
+	desc.addSection(describeIssuedTLS(d.client, item.Namespace, item.Name, item.Spec.TLS))
+
+	desc.Monitor = item.Spec.Monitor
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindProxySQL, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+// describeBackend follows the backend reference of a ProxySQL and describes
+// the database it points to.
+func (d *ProxySQLDescriber) describeBackend(item *api.ProxySQL) (*Section, error) {
+	s := newSection("Backend")
+	if item.Spec.Backend == nil || item.Spec.Backend.Ref == nil {
+		return s, nil
+	}
+	ref := item.Spec.Backend.Ref
+	if item.Spec.Backend.Replicas != nil {
+		s.addField("Replicas", "%d", *item.Spec.Backend.Replicas)
+	}
+
+	var (
+		phase    api.DatabasePhase
+		replicas *int32
+		err      error
+	)
+	switch ref.Kind {
+	case api.ResourceKindMySQL:
+		var db *api.MySQL
+		db, err = d.kubedb.MySQLs(item.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err == nil {
+			phase, replicas = db.Status.Phase, db.Spec.Replicas
+		}
+	case api.ResourceKindPerconaXtraDB:
+		var db *api.PerconaXtraDB
+		db, err = d.kubedb.PerconaXtraDBs(item.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err == nil {
+			phase, replicas = db.Status.Phase, db.Spec.Replicas
+		}
+	default:
+		s.addField("Name", "%s", ref.Name)
+		s.addField("Kind", "%s (unsupported)", ref.Kind)
+		return s, nil
+	}
+
+	s.addField("Name", "%s", ref.Name)
+	s.addField("Kind", "%s", ref.Kind)
+	if kerr.IsNotFound(err) {
+		s.addField("Warning", "dangling reference, %s %s/%s does not exist", ref.Kind, item.Namespace, ref.Name)
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	s.addField("Phase", "%s", phase)
+	s.addField("Database Replicas", "%d", types.Int32(replicas))
+	return s, nil
+}
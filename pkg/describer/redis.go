@@ -18,7 +18,9 @@ package describer
 
 import (
 	"context"
-	"io"
+	"fmt"
+	"sort"
+	"strings"
 
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
 	"kubedb.dev/apimachinery/client/clientset/versioned/scheme"
@@ -29,26 +31,34 @@ import (
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/describe"
-	"kmodules.xyz/client-go/discovery"
 	appcat_cs "kmodules.xyz/custom-resources/client/clientset/versioned"
-	stashV1beta1 "stash.appscode.dev/apimachinery/apis/stash/v1beta1"
 	stash "stash.appscode.dev/apimachinery/client/clientset/versioned"
 )
 
 type RedisDescriber struct {
-	client kubernetes.Interface
-	kubedb cs.KubedbV1alpha1Interface
-	stash  stash.Interface
-	appcat appcat_cs.Interface
+	client  kubernetes.Interface
+	kubedb  cs.KubedbV1alpha1Interface
+	stash   stash.Interface
+	appcat  appcat_cs.Interface
+	dynamic dynamic.Interface
 }
 
 func (d *RedisDescriber) Describe(namespace, name string, describerSettings describe.DescriberSettings) (string, error) {
-	item, err := d.kubedb.Redises(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	desc, err := d.DescribeDatabase(namespace, name, describerSettings)
 	if err != nil {
 		return "", err
 	}
+	return printDescription(desc)
+}
+
+func (d *RedisDescriber) DescribeDatabase(namespace, name string, describerSettings describe.DescriberSettings) (*DatabaseDescription, error) {
+	item, err := d.kubedb.Redises(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
 
 	selector := labels.SelectorFromSet(item.OffshootSelectors())
 
@@ -56,67 +66,114 @@ func (d *RedisDescriber) Describe(namespace, name string, describerSettings desc
 	if describerSettings.ShowEvents {
 		events, err = d.client.CoreV1().Events(item.Namespace).Search(scheme.Scheme, item)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	return d.describeRedis(item, selector, events)
 }
 
-func (d *RedisDescriber) describeRedis(item *api.Redis, selector labels.Selector, events *core.EventList) (string, error) {
-	return tabbedString(func(out io.Writer) error {
-		w := describe.NewPrefixWriter(out)
-		w.Write(LEVEL_0, "Name:\t%s\n", item.Name)
-		w.Write(LEVEL_0, "Namespace:\t%s\n", item.Namespace)
-		w.Write(LEVEL_0, "CreationTimestamp:\t%s\n", timeToString(&item.CreationTimestamp))
-		printLabelsMultiline(LEVEL_0, w, "Labels", item.Labels)
-		printAnnotationsMultiline(LEVEL_0, w, "Annotations", item.Annotations)
-
-		if item.Spec.Replicas != nil {
-			w.Write(LEVEL_0, "Replicas:\t%d  total\n", types.Int32(item.Spec.Replicas))
-		}
-		w.Write(LEVEL_0, "Status:\t%s\n", string(item.Status.Phase))
-		if len(item.Status.Reason) > 0 {
-			w.Write(LEVEL_0, "Reason:\t%s\n", item.Status.Reason)
-		}
+func (d *RedisDescriber) describeRedis(item *api.Redis, selector labels.Selector, events *core.EventList) (*DatabaseDescription, error) {
+	desc := newDatabaseDescription(api.ResourceKindRedis, item.ObjectMeta)
+	desc.Spec = SpecSummary{
+		Version:           string(item.Spec.Version),
+		Mode:              string(item.Spec.Mode),
+		Replicas:          item.Spec.Replicas,
+		StorageType:       item.Spec.StorageType,
+		Storage:           item.Spec.Storage,
+		Paused:            item.Spec.Paused,
+		Halted:            types.BoolP(item.Spec.Halted),
+		TerminationPolicy: item.Spec.TerminationPolicy,
+	}
+	desc.Status = StatusSummary{Phase: item.Status.Phase, Reason: item.Status.Reason}
 
-		describeStorage(item.Spec.StorageType, item.Spec.Storage, w)
+	desc.ResolvedVersion = describeResolvedVersion(d.dynamic, api.ResourceKindRedis, string(item.Spec.Version))
 
-		w.Write(LEVEL_0, "Paused:\t%v\n", item.Spec.Paused)
-		w.Write(LEVEL_0, "Halted:\t%v\n", item.Spec.Halted)
-		w.Write(LEVEL_0, "Termination Policy:\t%v\n", item.Spec.TerminationPolicy)
+	desc.Workloads, desc.Services = describeWorkloads(d.client, item.Namespace, selector)
 
-		showWorkload(d.client, item.Namespace, selector, w)
+	if item.Spec.Mode == api.RedisModeCluster && item.Spec.Cluster != nil {
+		desc.addSection(describeRedisCluster(item.Spec.Cluster))
+		desc.addSection(describeRedisShards(d.client, item))
+	}
 
-		if item.Spec.Monitor != nil {
-			describeMonitor(item.Spec.Monitor, w)
-		}
+	// Redis does not support TLS yet
+	desc.addSection(describeTLS(d.client, item.Namespace, nil, nil))
 
-		ab, err := d.appcat.AppcatalogV1alpha1().AppBindings(item.Namespace).Get(context.TODO(), item.Name, metav1.GetOptions{})
-		if err != nil && !kerr.IsNotFound(err) {
-			return err
-		}
+	desc.Monitor = item.Spec.Monitor
 
-		// Show Backup information
-		if discovery.ExistsGroupKind(d.client.Discovery(), stashV1beta1.SchemeGroupVersion.Group, stashV1beta1.ResourceKindBackupBlueprint) {
-			err = showBackups(d.stash, ab, w)
-			if err != nil {
-				return err
-			}
+	ab, err := getAppBinding(d.appcat, item.Namespace, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	desc.Backups, err = describeBackups(d.client, d.stash, ab)
+	if err != nil {
+		return nil, err
+	}
+	desc.AppBinding = ab
+
+	desc.OpsRequests, desc.OpsRequestsError = describeOpsRequests(d.client, d.dynamic, api.ResourceKindRedis, item.ObjectMeta)
+
+	desc.Events = describeEvents(events)
+
+	return desc, nil
+}
+
+func describeRedisCluster(cluster *api.RedisClusterSpec) *Section {
+	s := newSection("Cluster")
+	s.addField("Masters", "%d", types.Int32(cluster.Master))
+	s.addField("Replicas Per Master", "%d", types.Int32(cluster.Replicas))
+	return s
+}
+
+// describeRedisShards maps each shard StatefulSet of a cluster mode Redis to its
+// pods and flags shards whose running pods don't match the spec. Every shard
+// runs one master and Cluster.Replicas replicas.
+func describeRedisShards(client kubernetes.Interface, item *api.Redis) *Section {
+	expected := 1 + int(types.Int32(item.Spec.Cluster.Replicas))
+
+	s := newSection("Shards")
+	t := s.setTable("StatefulSet", "Pods", "Running", "Status")
+	for i := 0; i < int(types.Int32(item.Spec.Cluster.Master)); i++ {
+		name := item.StatefulSetNameWithShard(i)
+		sts, err := client.AppsV1().StatefulSets(item.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if kerr.IsNotFound(err) {
+			t.addRow(name, ValueNone, fmt.Sprintf("0/%d", expected), "Missing")
+			continue
+		} else if err != nil {
+			t.addRow(name, ValueNone, "-", fmt.Sprintf("Error: %v", err))
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			t.addRow(name, ValueNone, "-", fmt.Sprintf("Error: %v", err))
+			continue
+		}
+		pods, err := client.CoreV1().Pods(item.Namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			t.addRow(name, ValueNone, "-", fmt.Sprintf("Error: %v", err))
+			continue
 		}
 
-		// Show AppBinding
-		if ab != nil {
-			err = showAppBinding(ab, w)
-			if err != nil {
-				return err
+		running := 0
+		names := make([]string, 0, len(pods.Items))
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+			if pod.Status.Phase == core.PodRunning {
+				running++
 			}
 		}
-
-		if events != nil {
-			DescribeEvents(events, w)
+		sort.Strings(names)
+		status := "OK"
+		if running != expected {
+			status = "Mismatch"
 		}
-
-		return nil
-	})
+		podNames := ValueNone
+		if len(names) > 0 {
+			podNames = strings.Join(names, ",")
+		}
+		t.addRow(name, podNames, fmt.Sprintf("%d/%d", running, expected), status)
+	}
+	return s
 }
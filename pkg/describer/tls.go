@@ -0,0 +1,211 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describer
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"strings"
+	"time"
+
+	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CertificateExpiryWindow is the duration before NotAfter in which a
+// certificate is highlighted as expiring soon.
+var CertificateExpiryWindow = 30 * 24 * time.Hour
+
+const (
+	// annotations cert-manager sets on the secrets of the certificates it issues
+	certManagerIssuerNameKey = "cert-manager.io/issuer-name"
+	certManagerIssuerKindKey = "cert-manager.io/issuer-kind"
+)
+
+// certSecretNames returns the names of the certificate secrets the operator
+// creates for a database.
+func certSecretNames(name string, suffixes ...string) []string {
+	names := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		names = append(names, name+suffix)
+	}
+	return names
+}
+
+// issuedCertSecretNames returns the names of the TLS secrets in the namespace
+// that cert-manager issued from the issuer of a database and whose names start
+// with the name of the database. It is used for databases whose certificate
+// secret names are not known.
+func issuedCertSecretNames(client kubernetes.Interface, namespace, name string, tls *api.TLSConfig) ([]string, error) {
+	if tls == nil || tls.IssuerRef == nil {
+		return nil, nil
+	}
+	secrets, err := client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", string(core.SecretTypeTLS)).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, secret := range secrets.Items {
+		if !strings.HasPrefix(secret.Name, name+"-") ||
+			secret.Annotations[certManagerIssuerNameKey] != tls.IssuerRef.Name {
+			continue
+		}
+		if kind, ok := secret.Annotations[certManagerIssuerKindKey]; ok && kind != tls.IssuerRef.Kind {
+			continue
+		}
+		names = append(names, secret.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// describeIssuedTLS describes the TLS configuration of a database along with
+// the certificates found by issuedCertSecretNames.
+func describeIssuedTLS(client kubernetes.Interface, namespace, name string, tls *api.TLSConfig) *Section {
+	names, err := issuedCertSecretNames(client, namespace, name, tls)
+	s := describeTLS(client, namespace, tls, names)
+	if err != nil {
+		s.addField("Error", "can not list certificate secrets: %v", err)
+	}
+	return s
+}
+
+// describeTLS describes the TLS configuration of a database along with the
+// certificates stored in the given secrets. Secrets that don't exist, e.g. the
+// exporter certificate of a database without monitoring, are left out.
+func describeTLS(client kubernetes.Interface, namespace string, tls *api.TLSConfig, secretNames []string) *Section {
+	s := newSection("TLS")
+	if tls == nil {
+		return s
+	}
+	if tls.IssuerRef != nil {
+		issuer := s.addSection("Issuer")
+		if tls.IssuerRef.APIGroup != nil {
+			issuer.addField("APIGroup", "%s", *tls.IssuerRef.APIGroup)
+		}
+		issuer.addField("Kind", "%s", tls.IssuerRef.Kind)
+		issuer.addField("Name", "%s", tls.IssuerRef.Name)
+	}
+	if cert := tls.Certificate; cert != nil {
+		c := s.addSection("Certificate")
+		c.addField("Organization", "%s", joinOrNone(cert.Organization))
+		c.addField("DNS Names", "%s", joinOrNone(cert.DNSNames))
+		if len(cert.IPAddresses) > 0 {
+			c.addField("IP Addresses", "%s", strings.Join(cert.IPAddresses, ", "))
+		}
+		if len(cert.URISANs) > 0 {
+			c.addField("URI SANs", "%s", strings.Join(cert.URISANs, ", "))
+		}
+		if cert.Duration != nil {
+			c.addField("Duration", "%s", cert.Duration.Duration)
+		}
+		if cert.RenewBefore != nil {
+			c.addField("Renew Before", "%s", cert.RenewBefore.Duration)
+		}
+	}
+
+	addCertificates(s, client, namespace, secretNames, true)
+	return s
+}
+
+// addCertificates adds every PEM encoded certificate found in the given secrets.
+// Missing secrets are reported unless ignoreMissing is set.
+func addCertificates(s *Section, client kubernetes.Interface, namespace string, secretNames []string, ignoreMissing bool) {
+	certs := s.addSection("Certificates")
+	for _, name := range secretNames {
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if ignoreMissing && kerr.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			certs.addField(name, "%v", err)
+			continue
+		}
+
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, cert := range parseCertificates(secret.Data[k]) {
+				describeCertificate(certs.addSection(name+"/"+k), cert)
+			}
+		}
+	}
+}
+
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		certs = append(certs, cert)
+	}
+}
+
+func describeCertificate(s *Section, cert *x509.Certificate) {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	s.addField("Subject", "%s", cert.Subject.String())
+	s.addField("SANs", "%s", joinOrNone(sans))
+	s.addField("Issuer", "%s", cert.Issuer.String())
+	s.addField("NotBefore", "%s", cert.NotBefore.Format(time.RFC1123Z))
+	s.addField("NotAfter", "%s", cert.NotAfter.Format(time.RFC1123Z))
+
+	left := time.Until(cert.NotAfter)
+	s.addField("Days Left", "%d", int(left.Hours()/24))
+	switch {
+	case left <= 0:
+		s.addField("Status", "EXPIRED")
+	case left <= CertificateExpiryWindow:
+		s.addField("Status", "EXPIRING SOON")
+	default:
+		s.addField("Status", "Valid")
+	}
+}
+
+func joinOrNone(list []string) string {
+	if len(list) == 0 {
+		return ValueNone
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the PolyForm Noncommercial License 1.0.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://github.com/appscode/licenses/raw/1.0.0/PolyForm-Noncommercial-1.0.0.md
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Printer prints a value in a structured output format. Unlike the printers
+// from cli-runtime it accepts any value that can be marshaled to JSON.
+type Printer interface {
+	Print(obj interface{}, w io.Writer) error
+}
+
+// NewPrinter returns the printer for an output format. Supported formats are
+// json, yaml and jsonpath=<template>.
+func NewPrinter(output string) (Printer, error) {
+	switch {
+	case output == "json":
+		return &JSONPrinter{}, nil
+	case output == "yaml":
+		return &YAMLPrinter{}, nil
+	case strings.HasPrefix(output, "jsonpath="):
+		return NewJSONPathPrinter(strings.TrimPrefix(output, "jsonpath="))
+	}
+	return nil, fmt.Errorf("unable to match a printer suitable for the output format %q, allowed formats are: json,yaml,jsonpath", output)
+}
+
+type JSONPrinter struct{}
+
+func (p *JSONPrinter) Print(obj interface{}, w io.Writer) error {
+	data, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+type YAMLPrinter struct{}
+
+func (p *YAMLPrinter) Print(obj interface{}, w io.Writer) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type JSONPathPrinter struct {
+	rawTemplate string
+	*jsonpath.JSONPath
+}
+
+// NewJSONPathPrinter parses a JSONPath template. Like kubectl, the surrounding
+// braces may be omitted, e.g. ".items[*].name".
+func NewJSONPathPrinter(tmpl string) (*JSONPathPrinter, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("template format specified but no template given")
+	}
+	if !strings.Contains(tmpl, "{") {
+		tmpl = fmt.Sprintf("{%s}", tmpl)
+	}
+	j := jsonpath.New("out")
+	if err := j.Parse(tmpl); err != nil {
+		return nil, err
+	}
+	return &JSONPathPrinter{
+		rawTemplate: tmpl,
+		JSONPath:    j,
+	}, nil
+}
+
+// Print evaluates the template against the JSON form of obj, so that field
+// names in the template match the json tags of obj.
+func (p *JSONPathPrinter) Print(obj interface{}, w io.Writer) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var queryObj interface{}
+	if err := json.Unmarshal(data, &queryObj); err != nil {
+		return err
+	}
+	if err := p.JSONPath.Execute(w, queryObj); err != nil {
+		return fmt.Errorf("error executing jsonpath %q: %v", p.rawTemplate, err)
+	}
+	return nil
+}